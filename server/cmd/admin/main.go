@@ -0,0 +1,94 @@
+// Command admin runs maintenance tasks against the database of the API.
+//
+// Usage:
+//
+//	admin bootstrap -email admin@example.com -name "Site Admin"
+//
+// bootstrap creates the administrator account if needed, makes sure the Administrator role
+// grants every permission ("*") and assigns it. The password of a new account is read from
+// ADMIN_PASSWORD, or from -password. Running it again leaves an existing account untouched.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/repositories"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/service"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/password"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "bootstrap" {
+		fmt.Fprintln(os.Stderr, "usage: admin bootstrap -email <email> -name <full name> [-password <password>]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	email := fs.String("email", os.Getenv("ADMIN_EMAIL"), "administrator email (ADMIN_EMAIL)")
+	name := fs.String("name", os.Getenv("ADMIN_FULL_NAME"), "administrator full name, used when the account is created (ADMIN_FULL_NAME)")
+	pass := fs.String("password", os.Getenv("ADMIN_PASSWORD"), "password, used when the account is created (ADMIN_PASSWORD)")
+	_ = fs.Parse(os.Args[2:])
+
+	cfg, err := platform.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	dbPool, err := platform.NewPostgresDatabase(cfg.DBConnString)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	// Events keep the other modules and caches in sync with the roles changed here.
+	nc, err := platform.NewNatsConnection(cfg.NatsURL)
+	if err != nil {
+		log.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm:  cfg.PasswordHashAlgorithm,
+		BcryptCost: cfg.PasswordBcryptCost,
+		Argon2id: password.Argon2id{
+			Memory:      cfg.PasswordArgon2Memory,
+			Iterations:  cfg.PasswordArgon2Iterations,
+			Parallelism: cfg.PasswordArgon2Parallelism,
+		},
+	})
+	if err != nil {
+		log.Fatalf("invalid password hashing config: %v", err)
+	}
+
+	svc := service.NewAuthService(repositories.NewPgxRepository(dbPool), nc, service.Config{
+		PasswordPolicy: password.Policy{
+			MinLength:     cfg.PasswordMinLength,
+			MaxBytes:      cfg.PasswordMaxBytes,
+			CheckBreached: cfg.PasswordCheckBreached,
+		},
+		Hasher: hasher,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := svc.BootstrapAdmin(ctx, domain.BootstrapAdmin{Email: *email, FullName: *name, Password: *pass})
+	if err != nil {
+		log.Fatalf("bootstrap failed: %v", err)
+	}
+	if err := nc.Flush(); err != nil {
+		slog.Warn("failed to flush events", "error", err)
+	}
+
+	slog.Info("administrator ready",
+		"user_id", result.UserID, "user_created", result.UserCreated,
+		"role_id", result.RoleID, "role_created", result.RoleCreated)
+}
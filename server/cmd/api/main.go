@@ -9,16 +9,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/cors"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth"
-	authHttp "github.com/rubenalves-dev/template-fullstack/server/internal/auth/delivery/http"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/platform"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
 )
 
@@ -48,17 +50,30 @@ func main() {
 	defer nc.Close()
 	logger.Info("connected to NATS")
 
+	// Audit Module, first so the other modules can record to it
+	auditModule := audit.NewModule(dbPool, nc)
+
+	trustedProxies, err := httputil.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Error("failed to parse TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+
 	router := chi.NewRouter()
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(httputil.RealIP(trustedProxies))
+	router.Use(auditModule.Middleware())
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 
+	if cfg.SessionCookies && slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		logger.Warn("browser sessions are enabled but CORS_ALLOWED_ORIGINS allows any origin; cross-origin clients cannot send cookies")
+	}
 	cors := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Adjust as needed
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "Retry-After", "X-Impersonator-ID"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	})
@@ -70,18 +85,24 @@ func main() {
 	})
 
 	// Auth Module
-	authModule := auth.NewModule(dbPool, nc, cfg.JWTSecret)
+	authModule, err := auth.NewModule(dbPool, nc, cfg, auditModule.Recorder)
+	if err != nil {
+		logger.Error("failed to initialise auth module", "error", err)
+		os.Exit(1)
+	}
 	authModule.RegisterRoutes(router)
+	auditModule.RegisterPermissions()
 
 	// Microservices
-	cmsModule := cms.NewModule(dbPool, nc)
+	cmsModule := cms.NewModule(dbPool, nc, authModule.Authorizer)
 
 	// Protected routes modules
 	router.Group(func(r chi.Router) {
-		r.Use(authHttp.AuthMiddleware(cfg.JWTSecret))
+		r.Use(authModule.Middleware())
 
 		authModule.RegisterProtectedRoutes(r)
-		cmsModule.RegisterRoutes(r)
+		cmsModule.RegisterRoutes(r, authModule.Guard)
+		auditModule.RegisterRoutes(r, authModule.Guard)
 	})
 
 	server := &http.Server{
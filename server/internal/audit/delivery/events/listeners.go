@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"log"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// queueGroup makes a single replica record each event.
+const queueGroup = "audit"
+
+// auditedSubjects are the event streams recorded in the audit log.
+var auditedSubjects = []string{"auth.>", "cms.>"}
+
+// ignoredSubjects describe no change of their own.
+var ignoredSubjects = map[string]bool{
+	events.AuthCacheInvalidate: true,
+}
+
+type eventHandler struct {
+	svc domain.Service
+}
+
+func RegisterListeners(nc *nats.Conn, svc domain.Service) {
+	h := &eventHandler{svc: svc}
+
+	for _, subject := range auditedSubjects {
+		_, err := nc.QueueSubscribe(subject, queueGroup, h.handleEvent)
+		if err != nil {
+			log.Printf("Failed to subscribe to %s: %v", subject, err)
+		}
+	}
+}
+
+func (h *eventHandler) handleEvent(m *nats.Msg) {
+	if ignoredSubjects[m.Subject] {
+		return
+	}
+	if err := h.svc.RecordEvent(context.Background(), m.Subject, audit.MetaFromMsg(m), m.Data); err != nil {
+		log.Printf("Failed to record audit entry for %s: %v", m.Subject, err)
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+type AuditHandler struct {
+	svc domain.Service
+}
+
+func RegisterHTTPHandlers(r chi.Router, svc domain.Service, guard authz.Guard) {
+	h := &AuditHandler{svc: svc}
+
+	r.With(guard.RequirePermission(domain.PermissionLogRead)).Get("/backoffice/audit", h.List)
+}
+
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	page := httputil.ParsePagination(r)
+	query := r.URL.Query()
+
+	filter := domain.Filter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		Limit:      page.PageSize,
+		Offset:     page.Offset(),
+	}
+	if v := query.Get("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "actor_id must be a UUID")
+			return
+		}
+		filter.ActorID = &id
+	}
+	for name, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*dst = &t
+	}
+
+	entries, total, err := h.svc.List(r.Context(), filter)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, jsonutil.PageResponse{
+		Items:    entries,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	})
+}
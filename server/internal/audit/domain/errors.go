@@ -0,0 +1,5 @@
+package domain
+
+import "github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+
+var ErrInvalidFilter = httputil.NewCodedError(httputil.ErrBadRequest, "INVALID_AUDIT_FILTER", "to must be after from")
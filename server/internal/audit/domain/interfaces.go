@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entry *Entry) error
+	List(ctx context.Context, filter Filter) ([]Entry, int, error)
+}
+
+type Service interface {
+	audit.Recorder
+
+	// RecordEvent stores an entry for an event received from NATS, with the metadata its publisher attached.
+	RecordEvent(ctx context.Context, subject string, meta audit.Meta, payload []byte) error
+	List(ctx context.Context, filter Filter) ([]Entry, int, error)
+}
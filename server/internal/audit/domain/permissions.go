@@ -0,0 +1,11 @@
+package domain
+
+import "github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+
+const PermissionLogRead = "audit.log.read"
+
+func GetAvailablePermissions() []authz.PermissionDefinition {
+	return []authz.PermissionDefinition{
+		{ID: PermissionLogRead, Description: "View the audit log", Group: "Audit", Order: 10},
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is a recorded change. Action is the event subject or, for changes recorded directly, an
+// action name in the same dotted form. Changes holds the event payload, which carries the
+// before/after summary where the publisher provides one.
+type Entry struct {
+	ID             uuid.UUID       `json:"id"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	ActorID        *uuid.UUID      `json:"actor_id"`
+	ImpersonatorID *uuid.UUID      `json:"impersonator_id,omitempty"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type,omitempty"`
+	TargetID       string          `json:"target_id,omitempty"`
+	Changes        json.RawMessage `json:"changes,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	IPAddress      string          `json:"ip_address,omitempty"`
+}
+
+// Filter narrows the audit log. Action matches exactly, or by prefix when it ends with "*".
+// From is inclusive and To exclusive.
+type Filter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
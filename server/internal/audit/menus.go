@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/menu"
+)
+
+var MenuDefinition = menu.MenuDefinition{
+	ID:          "audit:log",
+	Label:       "Audit Log",
+	Path:        "/system/audit",
+	Icon:        "history",
+	Order:       95,
+	Visible:     true,
+	Permissions: []string{domain.PermissionLogRead},
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"encoding/json"
+	"log"
+	nethttp "net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/delivery/events"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/delivery/http"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/repositories"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/service"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	menuDomain "github.com/rubenalves-dev/template-fullstack/server/internal/platform/menu"
+	globalEvents "github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// AuditModule records changes published on the auth.* and cms.* subjects, and those the other
+// modules hand to Recorder directly.
+type AuditModule struct {
+	Service  domain.Service
+	Recorder audit.Recorder
+
+	nc *nats.Conn
+}
+
+// NewModule is created before the other modules so they can be given the recorder.
+func NewModule(pool *pgxpool.Pool, nc *nats.Conn) *AuditModule {
+	repo := repositories.NewPgxRepository(pool)
+	svc := service.NewAuditService(repo)
+
+	events.RegisterListeners(nc, svc)
+
+	return &AuditModule{Service: svc, Recorder: svc, nc: nc}
+}
+
+// RegisterPermissions publishes the module's permission and menu. It is called once the auth
+// module, which is created after this one, listens for them.
+func (m *AuditModule) RegisterPermissions() {
+	payload := globalEvents.SystemPermissionsRegisteredData{
+		Module:      "audit",
+		Permissions: domain.GetAvailablePermissions(),
+	}
+	data, _ := json.Marshal(payload)
+	if err := m.nc.Publish(globalEvents.SystemPermissionsRegister, data); err != nil {
+		log.Printf("[ERROR] Failed to publish permissions for audit module: %v", err)
+	}
+
+	menuPayload := globalEvents.SystemMenusRegisteredData{
+		Domain:  "audit",
+		Version: 1,
+		Menu:    []menuDomain.MenuDefinition{MenuDefinition},
+	}
+	menuData, _ := json.Marshal(menuPayload)
+	if err := m.nc.Publish(globalEvents.SystemMenusRegister, menuData); err != nil {
+		log.Printf("[ERROR] Failed to publish menus for audit module: %v", err)
+	}
+}
+
+// Middleware captures the request ID and client address of every request for the audit log.
+func (m *AuditModule) Middleware() func(next nethttp.Handler) nethttp.Handler {
+	return audit.Middleware
+}
+
+func (m *AuditModule) RegisterRoutes(r chi.Router, guard authz.Guard) {
+	http.RegisterHTTPHandlers(r, m.Service, guard)
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+)
+
+type pgxRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) domain.Repository {
+	return &pgxRepo{pool: pool}
+}
+
+const entryColumns = `id, occurred_at, actor_id, impersonator_id, action, target_type, target_id, changes, request_id, ip_address`
+
+func (r *pgxRepo) Create(ctx context.Context, e *domain.Entry) error {
+	query := `
+		INSERT INTO audit_log (id, actor_id, impersonator_id, action, target_type, target_id, changes, request_id, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING occurred_at
+	`
+	var changes []byte
+	if len(e.Changes) > 0 {
+		changes = e.Changes
+	}
+	err := r.pool.QueryRow(ctx, query, e.ID, e.ActorID, e.ImpersonatorID, e.Action, e.TargetType, e.TargetID, changes, e.RequestID, e.IPAddress).Scan(&e.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("audit repo create entry: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepo) List(ctx context.Context, filter domain.Filter) ([]domain.Entry, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if prefix, ok := strings.CutSuffix(filter.Action, "*"); ok {
+		args = append(args, escapeLike(prefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("action LIKE $%d", len(args)))
+	} else if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conditions = append(conditions, fmt.Sprintf("target_type = $%d", len(args)))
+	}
+	if filter.TargetID != "" {
+		args = append(args, filter.TargetID)
+		conditions = append(conditions, fmt.Sprintf("target_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("occurred_at < $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT %s, count(*) OVER () AS total
+		FROM audit_log
+		%s
+		ORDER BY occurred_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, entryColumns, where, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("audit repo list entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.Entry{}
+	total := 0
+	for rows.Next() {
+		var e domain.Entry
+		var changes []byte
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.ActorID, &e.ImpersonatorID, &e.Action, &e.TargetType, &e.TargetID, &changes, &e.RequestID, &e.IPAddress, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		e.Changes = changes
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("audit repo list entries: %w", err)
+	}
+
+	// Paging beyond the oldest matching entry leaves no row to carry count(*) OVER (), so the total is
+	// counted on its own with the same filters, minus the LIMIT and OFFSET arguments.
+	if len(entries) == 0 && filter.Offset > 0 {
+		countQuery := `SELECT count(*) FROM audit_log ` + where
+		if err := r.pool.QueryRow(ctx, countQuery, args[:len(args)-2]...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("audit repo count entries: %w", err)
+		}
+	}
+	return entries, total, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/audit/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+)
+
+type auditService struct {
+	repo domain.Repository
+}
+
+func NewAuditService(repo domain.Repository) domain.Service {
+	return &auditService{repo: repo}
+}
+
+// Record stores an entry on a best-effort basis; the action it describes has already happened and a
+// failure to audit it is logged rather than reported to the caller.
+func (s auditService) Record(ctx context.Context, entry audit.Entry) {
+	e := newEntry(audit.MetaFrom(ctx), entry.Action)
+	e.TargetType, e.TargetID = entry.TargetType, entry.TargetID
+	if entry.Changes != nil {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			slog.Error("failed to marshal audit changes", "action", entry.Action, "error", err)
+		}
+		e.Changes = changes
+	}
+
+	if err := s.repo.Create(ctx, e); err != nil {
+		slog.Error("failed to record audit entry", "action", entry.Action, "error", err)
+	}
+}
+
+func (s auditService) RecordEvent(ctx context.Context, subject string, meta audit.Meta, payload []byte) error {
+	e := newEntry(meta, subject)
+
+	// Numbers are kept as written so integer IDs such as role IDs are not turned into floats.
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err == nil {
+		e.Changes = payload
+		e.TargetType, e.TargetID = eventTarget(subject, fields)
+	}
+	return s.repo.Create(ctx, e)
+}
+
+func (s auditService) List(ctx context.Context, filter domain.Filter) ([]domain.Entry, int, error) {
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, 0, domain.ErrInvalidFilter
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func newEntry(meta audit.Meta, action string) *domain.Entry {
+	return &domain.Entry{
+		ID:             uuid.New(),
+		ActorID:        meta.ActorID,
+		ImpersonatorID: meta.ImpersonatorID,
+		Action:         action,
+		RequestID:      meta.RequestID,
+		IPAddress:      meta.IPAddress,
+	}
+}
+
+// eventTargets maps the leading "<module>.<resource>" segments of a subject to the target type of
+// its entries and the payload field holding the target ID.
+var eventTargets = map[string]struct{ targetType, field string }{
+	"auth.user":          {"user", "user_id"},
+	"auth.impersonation": {"user", "user_id"},
+	"auth.role":          {"role", "role_id"},
+	"auth.apikey":        {"api_key", "key_id"},
+	"auth.invitation":    {"invitation", "invitation_id"},
+	"auth.login":         {"login", "key"},
+	"auth.permissions":   {"module", "module"},
+	"cms.page":           {"page", "page_id"},
+}
+
+func eventTarget(subject string, fields map[string]any) (string, string) {
+	parts := strings.SplitN(subject, ".", 3)
+	if len(parts) < 2 {
+		return "", ""
+	}
+	target, ok := eventTargets[parts[0]+"."+parts[1]]
+	if !ok {
+		return "", ""
+	}
+
+	switch id := fields[target.field].(type) {
+	case string:
+		return target.targetType, id
+	case json.Number:
+		return target.targetType, id.String()
+	default:
+		return target.targetType, ""
+	}
+}
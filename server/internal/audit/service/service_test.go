@@ -0,0 +1,39 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEventTarget(t *testing.T) {
+	tests := []struct {
+		subject    string
+		payload    string
+		wantType   string
+		wantTarget string
+	}{
+		{"auth.user.role.assigned", `{"user_id":"7f1c","role_id":3}`, "user", "7f1c"},
+		{"auth.role.permissions.changed", `{"role_id":12,"added":[]}`, "role", "12"},
+		{"auth.apikey.revoked", `{"key_id":"k1","user_id":"u1"}`, "api_key", "k1"},
+		{"auth.login.locked", `{"scope":"ip","key":"10.0.0.1"}`, "login", "10.0.0.1"},
+		{"auth.permissions.purged", `{"permissions":["a.b"]}`, "module", ""},
+		{"cms.page.access.changed", `{"page_id":"p1","change":"owner"}`, "page", "p1"},
+		{"cms.unknown", `{"id":"x"}`, "", ""},
+		{"auth", `{}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			var fields map[string]any
+			dec := json.NewDecoder(strings.NewReader(tt.payload))
+			dec.UseNumber()
+			if err := dec.Decode(&fields); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+			gotType, gotTarget := eventTarget(tt.subject, fields)
+			if gotType != tt.wantType || gotTarget != tt.wantTarget {
+				t.Fatalf("expected %q/%q, got %q/%q", tt.wantType, tt.wantTarget, gotType, gotTarget)
+			}
+		})
+	}
+}
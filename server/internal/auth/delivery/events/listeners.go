@@ -33,6 +33,12 @@ func RegisterListeners(nc *nats.Conn, svc domain.Service) {
 	if err != nil {
 		log.Printf("Failed to subscribe to %s: %v", events.SystemMenusRegister, err)
 	}
+
+	// Every replica subscribes, without a queue group, so each one drops its own cached entries.
+	_, err = nc.Subscribe(events.AuthCacheInvalidate, h.handleCacheInvalidate)
+	if err != nil {
+		log.Printf("Failed to subscribe to %s: %v", events.AuthCacheInvalidate, err)
+	}
 }
 
 func (h *eventHandler) handlePermissionsRegister(m *nats.Msg) {
@@ -60,6 +66,20 @@ func (h *eventHandler) handleMenusRegister(m *nats.Msg) {
 	}
 }
 
+func (h *eventHandler) handleCacheInvalidate(m *nats.Msg) {
+	var payload events.AuthCacheInvalidateData
+	if err := json.Unmarshal(m.Data, &payload); err != nil {
+		log.Printf("Failed to unmarshal cache invalidation event: %v", err)
+		return
+	}
+
+	h.svc.ApplyCacheInvalidation(domain.CacheInvalidation{
+		UserIDs:  payload.UserIDs,
+		AllUsers: payload.AllUsers,
+		Menus:    payload.Menus,
+	})
+}
+
 func flattenMenuDefinitions(domainName string, nodes []menu.MenuDefinition, parentID string) []domain.MenuDefinition {
 	var defs []domain.MenuDefinition
 	for _, n := range nodes {
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+// Cookie and header names of browser sessions.
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token"
+	CSRFTokenHeader    = "X-CSRF-Token"
+	OIDCStateCookie    = "oidc_state"
+)
+
+// refreshCookiePath limits the refresh token to the endpoints that use it.
+const refreshCookiePath = "/auth"
+
+// oidcStateCookiePath limits the single sign-on state to the endpoints that use it.
+const oidcStateCookiePath = "/auth/oidc"
+
+// CookieConfig enables browser sessions. Logins then set the tokens as HttpOnly cookies instead of
+// returning them, and requests authenticated by cookie must echo the CSRF cookie in CSRFTokenHeader
+// (double submit). Bearer tokens and API keys keep working and need no CSRF token.
+type CookieConfig struct {
+	Enabled         bool
+	Domain          string
+	Secure          bool
+	SameSite        http.SameSite
+	RefreshTokenTTL time.Duration
+}
+
+// setSessionCookies stores a new token pair in cookies, with a fresh CSRF token that is returned so
+// the client does not have to read it back from the cookie.
+func (c CookieConfig) setSessionCookies(w http.ResponseWriter, tokens *domain.TokenPair) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	csrf := base64.RawURLEncoding.EncodeToString(b)
+	refreshMaxAge := int(c.RefreshTokenTTL.Seconds())
+
+	http.SetCookie(w, c.cookie(AccessTokenCookie, tokens.AccessToken, "/", tokens.ExpiresIn, true))
+	http.SetCookie(w, c.cookie(RefreshTokenCookie, tokens.RefreshToken, refreshCookiePath, refreshMaxAge, true))
+	// Not HttpOnly: the backoffice reads it to fill in the header.
+	http.SetCookie(w, c.cookie(CSRFTokenCookie, csrf, "/", refreshMaxAge, false))
+	return csrf, nil
+}
+
+func (c CookieConfig) clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, c.cookie(AccessTokenCookie, "", "/", -1, true))
+	http.SetCookie(w, c.cookie(RefreshTokenCookie, "", refreshCookiePath, -1, true))
+	http.SetCookie(w, c.cookie(CSRFTokenCookie, "", "/", -1, false))
+}
+
+// oidcStateCookie binds a single sign-on login to the browser that started it. It is set whether or not
+// browser sessions are enabled, and is always SameSite=Lax.
+func (c CookieConfig) oidcStateCookie(state string, maxAge int) *http.Cookie {
+	cookie := c.cookie(OIDCStateCookie, state, oidcStateCookiePath, maxAge, true)
+	cookie.SameSite = http.SameSiteLaxMode
+	return cookie
+}
+
+func (c CookieConfig) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.Domain,
+		MaxAge:   maxAge,
+		Secure:   c.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+}
+
+// validCSRF reports whether the request carries the CSRF cookie and echoes it in CSRFTokenHeader.
+// Safe methods never change state and always pass.
+func validCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie, err := r.Cookie(CSRFTokenCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) == 1
+}
+
+func renderCSRFError(w http.ResponseWriter) {
+	jsonutil.RenderError(w, http.StatusForbidden, "INVALID_CSRF_TOKEN", "Missing or invalid CSRF token")
+}
+
+// renderSession answers a successful login or refresh, in cookies when browser sessions are enabled.
+func (h *AuthHandler) renderSession(w http.ResponseWriter, tokens *domain.TokenPair) {
+	if !h.cookies.Enabled {
+		jsonutil.RenderJSON(w, http.StatusOK, newLoginResponse(tokens))
+		return
+	}
+
+	csrf, err := h.cookies.setSessionCookies(w, tokens)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+	jsonutil.RenderJSON(w, http.StatusOK, loginResponse{
+		ExpiresIn: tokens.ExpiresIn,
+		CSRFToken: csrf,
+
+		MFAEnrollmentRequired: tokens.MFAPending,
+	})
+}
+
+// refreshToken returns the refresh token of the body or, for browser sessions, of the cookie. Tokens
+// read from the cookie need a valid CSRF token; ok is false when the error response was rendered.
+func (h *AuthHandler) refreshToken(w http.ResponseWriter, r *http.Request, fromBody string) (string, bool) {
+	if fromBody != "" || !h.cookies.Enabled {
+		return fromBody, true
+	}
+	cookie, err := r.Cookie(RefreshTokenCookie)
+	if err != nil {
+		return "", true
+	}
+	if !validCSRF(r) {
+		renderCSRFError(w)
+		return "", false
+	}
+	return cookie.Value, true
+}
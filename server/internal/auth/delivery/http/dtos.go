@@ -1,12 +1,42 @@
 package http
 
+import (
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+// loginResponse carries the token pair, or only the CSRF token when browser sessions keep the pair
+// in cookies.
 type loginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
+	CSRFToken    string `json:"csrf_token,omitempty"`
+	// MFAEnrollmentRequired tells the client to send the user to the two-factor enrollment screen.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+}
+
+// mfaChallengeResponse replaces loginResponse when the account has two-factor authentication enabled.
+type mfaChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"`
+}
+
+type mfaLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
 }
 
 type registerRequest struct {
@@ -14,3 +44,57 @@ type registerRequest struct {
 	Password string `json:"password"`
 	FullName string `json:"full_name"`
 }
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type confirmEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type roleMFARequest struct {
+	Required bool `json:"required"`
+}
+
+// impersonationResponse carries the only token of an impersonation session; it cannot be refreshed.
+type impersonationResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresIn int       `json:"expires_in"`
+	SessionID uuid.UUID `json:"session_id"`
+	// MFAEnrollmentRequired is set when the user's roles require a second factor the actor did not use.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+}
+
+// createAPIKeyResponse is the only time the full key is returned.
+type createAPIKeyResponse struct {
+	APIKey *domain.APIKey `json:"api_key"`
+	Key    string         `json:"key"`
+}
@@ -2,36 +2,100 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
 )
 
 type AuthHandler struct {
-	svc domain.Service
+	svc     domain.Service
+	cookies CookieConfig
 }
 
-func RegisterHTTPHandlers(r *chi.Mux, svc domain.Service) {
-	h := &AuthHandler{svc: svc}
+func RegisterHTTPHandlers(r *chi.Mux, svc domain.Service, cookies CookieConfig) {
+	h := &AuthHandler{svc: svc, cookies: cookies}
 
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", h.Login)
+		r.Post("/login/mfa", h.CompleteMFALogin)
+		r.Post("/oidc/authorize", h.StartOIDCLogin)
+		r.Post("/oidc/callback", h.CompleteOIDCLogin)
 		r.Post("/register", h.Register)
+		r.Post("/invitations/accept", h.AcceptInvitation)
+		r.Post("/refresh", h.Refresh)
+		r.Post("/logout", h.Logout)
+		r.Post("/password/forgot", h.ForgotPassword)
+		r.Post("/password/reset", h.ResetPassword)
+		r.Post("/email/verify", h.ConfirmEmail)
+		r.Post("/email/resend", h.ResendVerificationEmail)
 	})
+
+	r.Get("/.well-known/jwks.json", h.JWKS)
 }
 
-func RegisterProtectedHTTPHandlers(r chi.Router, svc domain.Service) {
+func RegisterProtectedHTTPHandlers(r chi.Router, svc domain.Service, guard authz.Guard) {
 	h := &AuthHandler{svc: svc}
 
 	r.Route("/backoffice", func(r chi.Router) {
+		// Self-service routes only need an authenticated caller.
+		r.Get("/me", h.GetMe)
 		r.Get("/me/menu", h.GetMyMenu)
-		r.Get("/roles", h.GetRoles)
-		r.Post("/roles", h.CreateRole)
-		r.Post("/roles/{roleID}/permissions", h.AddPermissionToRole)
-		r.Post("/users/{userID}/roles", h.AssignRoleToUser)
+		r.Post("/impersonation/stop", h.StopImpersonation)
+
+		// Account security can only be managed from a user's own session, never with an API key or
+		// while impersonating.
+		r.Group(func(r chi.Router) {
+			r.Use(requireUserSession)
+			r.Patch("/me", h.UpdateMe)
+			r.Post("/me/password", h.ChangeMyPassword)
+			r.Get("/me/sessions", h.ListMySessions)
+			r.Delete("/me/sessions/{sessionID}", h.RevokeMySession)
+			r.Get("/me/mfa", h.GetMyMFAStatus)
+			r.Post("/me/mfa/totp", h.EnrollTOTP)
+			r.Post("/me/mfa/totp/confirm", h.ConfirmTOTP)
+			r.Post("/me/mfa/totp/disable", h.DisableTOTP)
+			r.Post("/me/mfa/recovery-codes", h.RegenerateRecoveryCodes)
+			r.Get("/me/api-keys", h.ListMyAPIKeys)
+			r.Post("/me/api-keys", h.CreateAPIKey)
+			r.Delete("/me/api-keys/{keyID}", h.RevokeMyAPIKey)
+		})
+
+		r.With(guard.RequirePermission(domain.PermissionRoleRead)).Get("/permissions", h.GetPermissions)
+		r.With(guard.RequirePermission(domain.PermissionRoleRead)).Get("/permissions/deprecated", h.GetDeprecatedPermissions)
+		r.With(guard.RequirePermission(domain.PermissionRoleDelete)).Delete("/permissions/deprecated", h.PurgeDeprecatedPermissions)
+		r.With(guard.RequirePermission(domain.PermissionRoleRead)).Get("/roles", h.GetRoles)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Post("/roles", h.CreateRole)
+		r.With(guard.RequirePermission(domain.PermissionRoleRead)).Get("/roles/{roleID}", h.GetRole)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Patch("/roles/{roleID}", h.RenameRole)
+		r.With(guard.RequirePermission(domain.PermissionRoleDelete)).Delete("/roles/{roleID}", h.DeleteRole)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Post("/roles/{roleID}/permissions", h.AddPermissionToRole)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Put("/roles/{roleID}/permissions", h.SetRolePermissions)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Put("/roles/{roleID}/parents", h.SetRoleParents)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Delete("/roles/{roleID}/permissions/{permissionID}", h.RemovePermissionFromRole)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite, domain.PermissionRoleWrite)).Delete("/roles/{roleID}/members/{userID}", h.UnassignRoleFromUser)
+		r.With(guard.RequirePermission(domain.PermissionRoleWrite)).Put("/roles/{roleID}/mfa", h.SetRoleRequireMFA)
+
+		r.With(guard.RequirePermission(domain.PermissionUserRead)).Get("/users", h.ListUsers)
+		r.With(guard.RequirePermission(domain.PermissionUserRead)).Get("/users/{userID}", h.GetUser)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite)).Patch("/users/{userID}", h.UpdateUser)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite)).Post("/users/{userID}/archive", h.ArchiveUser)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite)).Post("/users/{userID}/restore", h.RestoreUser)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite)).Post("/users/{userID}/unlock", h.UnlockUser)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite, domain.PermissionRoleWrite)).Post("/users/{userID}/roles", h.AssignRoleToUser)
+		r.With(requireUserSession, guard.RequirePermission(domain.PermissionUserImpersonate)).Post("/users/{userID}/impersonate", h.StartImpersonation)
+
+		r.With(guard.RequirePermission(domain.PermissionUserRead)).Get("/invitations", h.ListInvitations)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite, domain.PermissionRoleWrite)).Post("/invitations", h.CreateInvitation)
+		r.With(guard.RequirePermission(domain.PermissionUserWrite)).Delete("/invitations/{invitationID}", h.RevokeInvitation)
 	})
 }
 
@@ -42,14 +106,71 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.svc.Login(r.Context(), req.Email, req.Password)
+	result, err := h.svc.Login(r.Context(), req.Email, req.Password, sessionMeta(r))
+	if err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	h.renderLoginResult(w, result)
+}
+
+// renderLoginResult renders either the new session or the challenge for the second factor.
+func (h *AuthHandler) renderLoginResult(w http.ResponseWriter, result *domain.LoginResult) {
+	if result.Tokens == nil {
+		jsonutil.RenderJSON(w, http.StatusOK, mfaChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: result.ChallengeToken,
+			ExpiresIn:      result.ChallengeTTL,
+		})
+		return
+	}
+
+	h.renderSession(w, result.Tokens)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+	refreshToken, ok := h.refreshToken(w, r, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.svc.Refresh(r.Context(), refreshToken, sessionMeta(r))
 	if err != nil {
 		status, code := httputil.MapError(err)
 		jsonutil.RenderError(w, status, code, err.Error())
 		return
 	}
 
-	jsonutil.RenderJSON(w, http.StatusOK, loginResponse{Token: token})
+	h.renderSession(w, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+	refreshToken, ok := h.refreshToken(w, r, req.RefreshToken)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Logout(r.Context(), refreshToken); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+	if h.cookies.Enabled {
+		h.cookies.clearSessionCookies(w)
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -66,10 +187,71 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		status, code := httputil.MapError(err)
-		jsonutil.RenderError(w, status, code, err.Error())
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, map[string]string{"message": "User registered successfully, check your email to activate the account"})
+}
+
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// JWKS consumers expect the bare RFC 7517 document rather than the usual response envelope.
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(h.svc.JWKS())
+}
+
+func newLoginResponse(tokens *domain.TokenPair) loginResponse {
+	return loginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+
+		MFAEnrollmentRequired: tokens.MFAPending,
+	}
+}
+
+// decodeOptionalBody decodes the JSON body into v, accepting an empty body. Browser sessions send
+// refresh and logout requests without one, the refresh token being in a cookie.
+func decodeOptionalBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// renderLoginError renders a failed login, adding Retry-After when the attempt was throttled.
+func renderLoginError(w http.ResponseWriter, err error) {
+	var throttled *domain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	renderError(w, err)
+}
+
+// renderError maps err to an error response, including per-field details for validation errors.
+func renderError(w http.ResponseWriter, err error) {
+	status, code := httputil.MapError(err)
+
+	var invalid *httputil.ValidationError
+	if errors.As(err, &invalid) {
+		jsonutil.RenderFieldErrors(w, status, code, err.Error(), invalid.Fields)
 		return
 	}
+	jsonutil.RenderError(w, status, code, err.Error())
+}
 
-	jsonutil.RenderJSON(w, http.StatusCreated, map[string]string{"message": "User registered successfully"})
+// sessionMeta extracts the client details recorded on new sessions.
+// RemoteAddr has already been rewritten by httputil.RealIP when the request came through a trusted proxy.
+func sessionMeta(r *http.Request) domain.SessionMeta {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return domain.SessionMeta{
+		UserAgent: r.UserAgent(),
+		IPAddress: ip,
+	}
 }
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) ListMyAPIKeys(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.svc.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, keys)
+}
+
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	// Otherwise a key would let the user skip the second factor their role requires.
+	if claims.MFAPending {
+		err := domain.ErrMFAEnrollmentPending
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	var req domain.NewAPIKey
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	key, raw, err := h.svc.CreateAPIKey(r.Context(), userID, req)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, createAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+func (h *AuthHandler) RevokeMyAPIKey(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid API Key ID")
+		return
+	}
+
+	if err := h.svc.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
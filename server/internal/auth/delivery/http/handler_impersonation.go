@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) StartImpersonation(w http.ResponseWriter, r *http.Request) {
+	claims, actorID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	actorSessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid session in token")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	tokens, err := h.svc.StartImpersonation(r.Context(), actorID, actorSessionID, userID, sessionMeta(r))
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, impersonationResponse{
+		Token:     tokens.AccessToken,
+		TokenType: "Bearer",
+		ExpiresIn: tokens.ExpiresIn,
+		SessionID: tokens.SessionID,
+
+		MFAEnrollmentRequired: tokens.MFAPending,
+	})
+}
+
+func (h *AuthHandler) StopImpersonation(w http.ResponseWriter, r *http.Request) {
+	claims, _, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.StopImpersonation(r.Context(), claims); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
+}
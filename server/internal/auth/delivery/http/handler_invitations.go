@@ -0,0 +1,72 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.svc.ListInvitations(r.Context())
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, invitations)
+}
+
+func (h *AuthHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	_, actorID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.NewInvitation
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	invitation, err := h.svc.CreateInvitation(r.Context(), actorID, req)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *AuthHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := uuid.Parse(chi.URLParam(r, "invitationID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid Invitation ID")
+		return
+	}
+
+	if err := h.svc.RevokeInvitation(r.Context(), invitationID); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
+
+func (h *AuthHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req domain.InvitationAcceptance
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.AcceptInvitation(r.Context(), req); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, map[string]string{"message": "Account created, you can now sign in"})
+}
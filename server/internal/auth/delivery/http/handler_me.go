@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	me, err := h.svc.GetMe(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+	me.MFAPending = claims.MFAPending
+	if claims.IsImpersonated() {
+		if actorID, err := uuid.Parse(claims.Act.Subject); err == nil {
+			me.ImpersonatorID = &actorID
+		}
+	}
+	if claims.IsAPIKey() {
+		me.Scopes = claims.Scopes
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, me)
+}
+
+func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	user, err := h.svc.UpdateMe(r.Context(), userID, req)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, user)
+}
+
+func (h *AuthHandler) ChangeMyPassword(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid session in token")
+		return
+	}
+
+	var req changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.ChangePassword(r.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"message": "Password changed successfully"})
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) CompleteMFALogin(w http.ResponseWriter, r *http.Request) {
+	var req mfaLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	tokens, err := h.svc.CompleteMFALogin(r.Context(), req.ChallengeToken, req.Code, sessionMeta(r))
+	if err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	h.renderSession(w, tokens)
+}
+
+func (h *AuthHandler) GetMyMFAStatus(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	mfaStatus, err := h.svc.GetMFAStatus(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, mfaStatus)
+}
+
+func (h *AuthHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.svc.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, enrollment)
+}
+
+func (h *AuthHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid session ID in token")
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	codes, err := h.svc.ConfirmTOTP(r.Context(), userID, sessionID, req.Code)
+	if err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.DisableTOTP(r.Context(), userID, req.Code); err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "disabled"})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	codes, err := h.svc.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		renderLoginError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) SetRoleRequireMFA(w http.ResponseWriter, r *http.Request) {
+	roleID, err := strconv.Atoi(chi.URLParam(r, "roleID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_ID", "Invalid Role ID")
+		return
+	}
+
+	var req roleMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.SetRoleRequireMFA(r.Context(), roleID, req.Required); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]bool{"require_mfa": req.Required})
+}
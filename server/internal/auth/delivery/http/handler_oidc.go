@@ -0,0 +1,46 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.svc.StartOIDCLogin(r.Context())
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	http.SetCookie(w, h.cookies.oidcStateCookie(authorization.State, authorization.ExpiresIn))
+	jsonutil.RenderJSON(w, http.StatusOK, authorization)
+}
+
+func (h *AuthHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var req oidcCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "code and state are required")
+		return
+	}
+
+	var browserState string
+	if cookie, err := r.Cookie(OIDCStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	// The state is single-use whatever the outcome.
+	http.SetCookie(w, h.cookies.oidcStateCookie("", -1))
+
+	result, err := h.svc.CompleteOIDCLogin(r.Context(), req.Code, req.State, browserState, sessionMeta(r))
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	h.renderLoginResult(w, result)
+}
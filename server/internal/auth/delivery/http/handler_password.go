@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.ForgotPassword(r.Context(), req.Email); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If an account exists for this email, a reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
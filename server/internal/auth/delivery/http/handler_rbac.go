@@ -7,7 +7,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
 )
@@ -24,6 +23,27 @@ type addPermissionRequest struct {
 	PermissionID string `json:"permission_id"`
 }
 
+type renameRoleRequest struct {
+	Name string `json:"name"`
+}
+
+type setRolePermissionsRequest struct {
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+type setRoleParentsRequest struct {
+	ParentIDs []int `json:"parent_ids"`
+}
+
+type purgePermissionsResponse struct {
+	Purged []string `json:"purged"`
+}
+
+type rolePermissionsChangeResponse struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
 func (h *AuthHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
 	var req createRoleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -33,8 +53,7 @@ func (h *AuthHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
 
 	role, err := h.svc.CreateRole(r.Context(), req.Name)
 	if err != nil {
-		status, code := httputil.MapError(err)
-		jsonutil.RenderError(w, status, code, err.Error())
+		renderError(w, err)
 		return
 	}
 
@@ -100,16 +119,174 @@ func (h *AuthHandler) AddPermissionToRole(w http.ResponseWriter, r *http.Request
 	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "added"})
 }
 
-func (h *AuthHandler) GetMyMenu(w http.ResponseWriter, r *http.Request) {
-	claims, ok := r.Context().Value(domain.UserClaimsKey).(*domain.UserClaims)
+func (h *AuthHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	catalogue, err := h.svc.GetPermissionCatalogue(r.Context())
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, catalogue)
+}
+
+func (h *AuthHandler) GetDeprecatedPermissions(w http.ResponseWriter, r *http.Request) {
+	perms, err := h.svc.GetDeprecatedPermissions(r.Context())
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, perms)
+}
+
+func (h *AuthHandler) PurgeDeprecatedPermissions(w http.ResponseWriter, r *http.Request) {
+	purged, err := h.svc.PurgeDeprecatedPermissions(r.Context())
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, purgePermissionsResponse{Purged: purged})
+}
+
+func (h *AuthHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
 	if !ok {
-		jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not found in context")
 		return
 	}
 
-	userID, err := uuid.Parse(claims.UserID)
+	role, err := h.svc.GetRoleDetail(r.Context(), roleID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, role)
+}
+
+func (h *AuthHandler) RenameRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req renameRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	role, err := h.svc.RenameRole(r.Context(), roleID, req.Name)
 	if err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID in token")
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, role)
+}
+
+func (h *AuthHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteRole(r.Context(), roleID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RemovePermissionFromRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemovePermissionFromRole(r.Context(), roleID, chi.URLParam(r, "permissionID")); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) SetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req setRolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PermissionIDs == nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	added, removed, err := h.svc.SetRolePermissions(r.Context(), roleID, req.PermissionIDs)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, rolePermissionsChangeResponse{Added: added, Removed: removed})
+}
+
+func (h *AuthHandler) SetRoleParents(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req setRoleParentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ParentIDs == nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	parents, err := h.svc.SetRoleParents(r.Context(), roleID, req.ParentIDs)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, parents)
+}
+
+func (h *AuthHandler) UnassignRoleFromUser(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := roleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	if err := h.svc.UnassignRole(r.Context(), userID, roleID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) GetMyMenu(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -122,3 +299,12 @@ func (h *AuthHandler) GetMyMenu(w http.ResponseWriter, r *http.Request) {
 
 	jsonutil.RenderJSON(w, http.StatusOK, menu)
 }
+
+func roleIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	roleID, err := strconv.Atoi(chi.URLParam(r, "roleID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_ID", "Invalid Role ID")
+		return 0, false
+	}
+	return roleID, true
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) ListMySessions(w http.ResponseWriter, r *http.Request) {
+	claims, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.svc.ListSessions(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == claims.SessionID
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, sessions)
+}
+
+func (h *AuthHandler) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid Session ID")
+		return
+	}
+
+	if err := h.svc.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
+)
+
+func (h *AuthHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page := httputil.ParsePagination(r)
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.UserStatusActive, domain.UserStatusArchived, domain.UserStatusAll:
+	default:
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "status must be one of active, archived or all")
+		return
+	}
+
+	users, total, err := h.svc.ListUsers(r.Context(), domain.UserFilter{
+		Search: r.URL.Query().Get("search"),
+		Status: status,
+		Limit:  page.PageSize,
+		Offset: page.Offset(),
+	})
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, jsonutil.PageResponse{
+		Items:    users,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	})
+}
+
+func (h *AuthHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	user, err := h.svc.GetUser(r.Context(), userID)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, user)
+}
+
+func (h *AuthHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	var req domain.UserUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	user, err := h.svc.UpdateUser(r.Context(), userID, req)
+	if err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, user)
+}
+
+func (h *AuthHandler) ArchiveUser(w http.ResponseWriter, r *http.Request) {
+	_, actorID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	if err := h.svc.ArchiveUser(r.Context(), actorID, userID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "archived"})
+}
+
+func (h *AuthHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	if err := h.svc.RestoreUser(r.Context(), userID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+}
+
+func (h *AuthHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_UUID", "Invalid User ID")
+		return
+	}
+
+	if err := h.svc.UnlockUser(r.Context(), userID); err != nil {
+		status, code := httputil.MapError(err)
+		jsonutil.RenderError(w, status, code, err.Error())
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"status": "unlocked"})
+}
@@ -5,40 +5,117 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
 )
 
-func AuthMiddleware(jwtSecret string) func(next http.Handler) http.Handler {
+// ImpersonatorHeader names the staff member behind an impersonation token on every response.
+const ImpersonatorHeader = "X-Impersonator-ID"
+
+// AuthMiddleware authenticates the request with either a Bearer access token or a Bearer API key
+// and stores the resulting claims in the context. With browser sessions enabled, requests without an
+// Authorization header are authenticated by the access token cookie and, unless the method is safe,
+// must echo the CSRF cookie in the X-CSRF-Token header.
+func AuthMiddleware(svc domain.Service, cookies CookieConfig) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var token string
+			validate := svc.ValidateAccessToken
+
 			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			switch {
+			case authHeader != "":
+				parts := strings.Split(authHeader, " ")
+				if len(parts) != 2 || parts[0] != "Bearer" {
+					jsonutil.RenderError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid authorization header")
+					return
+				}
+				token = parts[1]
+				if strings.HasPrefix(token, domain.APIKeyPrefix) {
+					validate = svc.ValidateAPIKey
+				}
+			case cookies.Enabled:
+				cookie, err := r.Cookie(AccessTokenCookie)
+				if err != nil {
+					jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized")
+					return
+				}
+				if !validCSRF(r) {
+					renderCSRFError(w)
+					return
+				}
+				token = cookie.Value
+			default:
 				jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized")
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				jsonutil.RenderError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid authorization header")
+			claims, err := validate(r.Context(), token)
+			if err != nil {
+				jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired token")
 				return
 			}
 
-			tokenString := parts[1]
-			claims := &domain.UserClaims{}
-
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-				return []byte(jwtSecret), nil
-			})
-
-			if err != nil || !token.Valid {
-				jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired token")
-				return
+			if claims.IsImpersonated() {
+				w.Header().Set(ImpersonatorHeader, claims.Act.Subject)
 			}
 
 			ctx := context.WithValue(r.Context(), domain.UserClaimsKey, claims)
+			if userID, err := uuid.Parse(claims.UserID); err == nil {
+				var impersonatorID *uuid.UUID
+				if claims.IsImpersonated() {
+					if id, err := uuid.Parse(claims.Act.Subject); err == nil {
+						impersonatorID = &id
+					}
+				}
+				ctx = audit.WithActor(ctx, userID, impersonatorID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// currentUser returns the claims placed in the context by AuthMiddleware.
+// When they are missing or malformed it renders the error response and returns false.
+func currentUser(w http.ResponseWriter, r *http.Request) (*domain.UserClaims, uuid.UUID, bool) {
+	claims, ok := r.Context().Value(domain.UserClaimsKey).(*domain.UserClaims)
+	if !ok {
+		jsonutil.RenderError(w, http.StatusUnauthorized, "UNAUTHORIZED", "User not found in context")
+		return nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID in token")
+		return nil, uuid.Nil, false
+	}
+
+	return claims, userID, true
+}
+
+// requireUserSession keeps API keys and impersonation sessions away from routes that manage the
+// account itself, such as the password, sessions, second factors and other API keys.
+func requireUserSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _, ok := currentUser(w, r)
+		if !ok {
+			return
+		}
+		var err error
+		switch {
+		case claims.IsAPIKey():
+			err = domain.ErrAPIKeyNotAllowed
+		case claims.IsImpersonated():
+			err = domain.ErrImpersonationRestricted
+		}
+		if err != nil {
+			status, code := httputil.MapError(err)
+			jsonutil.RenderError(w, status, code, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// tokenService accepts a single access token.
+type tokenService struct {
+	domain.Service
+	token string
+}
+
+func (s tokenService) ValidateAccessToken(_ context.Context, token string) (*domain.UserClaims, error) {
+	if token != s.token {
+		return nil, httputil.ErrUnauthorized
+	}
+	return &domain.UserClaims{UserID: uuid.NewString()}, nil
+}
+
+func TestAuthMiddlewareCookieSessions(t *testing.T) {
+	mw := AuthMiddleware(tokenService{token: "jwt"}, CookieConfig{Enabled: true})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		method string
+		bearer string
+		cookie string
+		csrf   string
+		header string
+		want   int
+	}{
+		{"bearer needs no csrf", http.MethodPost, "jwt", "", "", "", http.StatusNoContent},
+		{"cookie on safe method", http.MethodGet, "", "jwt", "", "", http.StatusNoContent},
+		{"cookie with matching csrf", http.MethodPost, "", "jwt", "abc", "abc", http.StatusNoContent},
+		{"cookie without csrf header", http.MethodPost, "", "jwt", "abc", "", http.StatusForbidden},
+		{"cookie with wrong csrf", http.MethodDelete, "", "jwt", "abc", "abd", http.StatusForbidden},
+		{"csrf header without cookie", http.MethodPatch, "", "jwt", "", "abc", http.StatusForbidden},
+		{"invalid cookie", http.MethodGet, "", "other", "", "", http.StatusUnauthorized},
+		{"no credentials", http.MethodGet, "", "", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: tt.cookie})
+			}
+			if tt.csrf != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFTokenCookie, Value: tt.csrf})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFTokenHeader, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			mw(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+
+	t.Run("cookies ignored when disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "jwt"})
+		rec := httptest.NewRecorder()
+		AuthMiddleware(tokenService{token: "jwt"}, CookieConfig{})(ok).ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
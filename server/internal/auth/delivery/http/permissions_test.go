@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+// permissionService implements only the service call used by PermissionGuard.
+type permissionService struct {
+	domain.Service
+	perms []string
+}
+
+func (s permissionService) GetUserPermissions(_ context.Context, _ uuid.UUID) ([]string, error) {
+	return s.perms, nil
+}
+
+func TestPermissionGuard(t *testing.T) {
+	guard := NewPermissionGuard(permissionService{perms: []string{"cms.page.read", "cms.page.write"}})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name       string
+		middleware func(next http.Handler) http.Handler
+		mfaPending bool
+		want       int
+	}{
+		{"all granted", guard.RequirePermission("cms.page.read", "cms.page.write"), false, http.StatusNoContent},
+		{"one missing", guard.RequirePermission("cms.page.read", "cms.page.delete"), false, http.StatusForbidden},
+		{"any granted", guard.RequireAnyPermission("cms.page.delete", "cms.page.write"), false, http.StatusNoContent},
+		{"none granted", guard.RequireAnyPermission("auth.role.read"), false, http.StatusForbidden},
+		{"wildcard grant", NewPermissionGuard(permissionService{perms: []string{"cms.*"}}).RequirePermission("cms.page.delete"), false, http.StatusNoContent},
+		{"super admin", NewPermissionGuard(permissionService{perms: []string{"*"}}).RequirePermission("auth.role.delete"), false, http.StatusNoContent},
+		{"mfa enrollment pending", guard.RequirePermission("cms.page.read"), true, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			claims := &domain.UserClaims{UserID: uuid.NewString(), MFAPending: tt.mfaPending}
+			req = req.WithContext(context.WithValue(req.Context(), domain.UserClaimsKey, claims))
+
+			rec := httptest.NewRecorder()
+			tt.middleware(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestPermissionGuardRestrictsAPIKeysToScopes(t *testing.T) {
+	guard := NewPermissionGuard(permissionService{perms: []string{"cms.page.read", "cms.page.write"}})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		permission string
+		want       int
+	}{
+		{"within scope", []string{"cms.page.read"}, "cms.page.read", http.StatusNoContent},
+		{"outside scope", []string{"cms.page.read"}, "cms.page.write", http.StatusForbidden},
+		{"scope the owner lost", []string{"cms.page.delete"}, "cms.page.delete", http.StatusForbidden},
+		{"wildcard scope", []string{"cms.page.*"}, "cms.page.write", http.StatusNoContent},
+		{"wildcard scope beyond the owner", []string{"cms.page.*"}, "cms.page.delete", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			claims := &domain.UserClaims{UserID: uuid.NewString(), APIKeyID: uuid.NewString(), Scopes: tt.scopes}
+			req = req.WithContext(context.WithValue(req.Context(), domain.UserClaimsKey, claims))
+
+			rec := httptest.NewRecorder()
+			guard.RequirePermission(tt.permission)(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestPermissionGuardRestrictsImpersonation(t *testing.T) {
+	guard := NewPermissionGuard(permissionService{perms: []string{"*"}})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name       string
+		permission string
+		want       int
+	}{
+		{"regular permission", "cms.page.write", http.StatusNoContent},
+		{"role change", domain.PermissionRoleWrite, http.StatusForbidden},
+		{"account change", domain.PermissionUserWrite, http.StatusForbidden},
+		{"nested impersonation", domain.PermissionUserImpersonate, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			claims := &domain.UserClaims{UserID: uuid.NewString(), Act: &domain.Actor{Subject: uuid.NewString()}}
+			req = req.WithContext(context.WithValue(req.Context(), domain.UserClaimsKey, claims))
+
+			rec := httptest.NewRecorder()
+			guard.RequirePermission(tt.permission)(ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
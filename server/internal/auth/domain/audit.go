@@ -0,0 +1,10 @@
+package domain
+
+// Audit actions the auth service records directly, because no event is published for them.
+const (
+	AuditLoginSucceeded = "auth.login.succeeded"
+	AuditLoginFailed    = "auth.login.failed"
+)
+
+// AuditTargetUser is the target type of entries about a staff account.
+const AuditTargetUser = "user"
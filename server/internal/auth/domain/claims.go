@@ -7,7 +7,47 @@ type ContextKey string
 const UserClaimsKey ContextKey = "user_claims"
 
 // UserClaims represents the claims of a JWT token issued to a user.
+// MFAPending is set when one of the user's roles requires two-factor authentication but the
+// session was opened without it; such tokens only grant access to self-service routes.
+//
+// Requests authenticated with an API key get claims with APIKeyID and Scopes set instead of a session;
+// those two fields never appear in a JWT.
+//
+// Act is set on impersonation tokens: UserID is the impersonated user and Act names the staff member
+// acting as them (RFC 8693 actor claim).
 type UserClaims struct {
-	UserID string
+	UserID     string
+	SessionID  string   `json:"sid"`
+	AMR        []string `json:"amr,omitempty"`
+	MFAPending bool     `json:"mfa_pending,omitempty"`
+	Act        *Actor   `json:"act,omitempty"`
+	APIKeyID   string   `json:"-"`
+	Scopes     []string `json:"-"`
+	jwt.RegisteredClaims
+}
+
+// IsAPIKey reports whether the request was authenticated with an API key rather than a user session.
+func (c *UserClaims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// Actor identifies who is really behind an impersonation token.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonated reports whether the token was issued to a staff member acting as another user.
+func (c *UserClaims) IsImpersonated() bool {
+	return c.Act != nil
+}
+
+// MFAChallengeAudience is the audience of the challenge token returned by the first login step.
+const MFAChallengeAudience = "mfa-challenge"
+
+// MFAChallengeClaims are carried by the challenge token. It has no session, so it is never
+// accepted as an access token. AMR lists the methods already verified, which the session opened
+// after the second factor keeps.
+type MFAChallengeClaims struct {
+	AMR []string `json:"amr,omitempty"`
 	jwt.RegisteredClaims
 }
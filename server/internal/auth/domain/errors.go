@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+var (
+	ErrAccountNotActivated  = httputil.NewCodedError(httputil.ErrForbidden, "ACCOUNT_NOT_ACTIVATED", "account email has not been verified")
+	ErrAccountArchived      = httputil.NewCodedError(httputil.ErrForbidden, "ACCOUNT_ARCHIVED", "account has been archived")
+	ErrInvalidMFACode       = httputil.NewCodedError(httputil.ErrUnauthorized, "INVALID_MFA_CODE", "authentication code is invalid")
+	ErrMFAAlreadyEnabled    = httputil.NewCodedError(httputil.ErrConflict, "MFA_ALREADY_ENABLED", "two-factor authentication is already enabled")
+	ErrMFANotEnabled        = httputil.NewCodedError(httputil.ErrConflict, "MFA_NOT_ENABLED", "two-factor authentication is not enabled")
+	ErrMFARequiredByRole    = httputil.NewCodedError(httputil.ErrConflict, "MFA_REQUIRED_BY_ROLE", "two-factor authentication is required by one of your roles")
+	ErrMFAEnrollmentPending = httputil.NewCodedError(httputil.ErrForbidden, "MFA_ENROLLMENT_REQUIRED", "enable two-factor authentication to continue")
+	ErrTooManyAttempts      = httputil.NewCodedError(httputil.ErrTooManyRequests, "TOO_MANY_ATTEMPTS", "too many failed login attempts, try again later")
+	ErrLoginLocked          = httputil.NewCodedError(httputil.ErrTooManyRequests, "LOGIN_LOCKED", "login is temporarily locked after repeated failures")
+	ErrAPIKeyNotAllowed     = httputil.NewCodedError(httputil.ErrForbidden, "API_KEY_NOT_ALLOWED", "this endpoint requires a user session")
+	ErrInvalidToken         = httputil.NewCodedError(httputil.ErrUnauthorized, "INVALID_TOKEN", "token is invalid, expired or already used")
+	ErrRoleInUse            = httputil.NewCodedError(httputil.ErrConflict, "ROLE_IN_USE", "role is still assigned to users")
+	ErrUnknownPermission    = httputil.NewCodedError(httputil.ErrBadRequest, "UNKNOWN_PERMISSION", "permission does not exist")
+	ErrRoleHasChildren      = httputil.NewCodedError(httputil.ErrConflict, "ROLE_HAS_CHILDREN", "other roles inherit from this role")
+	ErrRoleCycle            = httputil.NewCodedError(httputil.ErrBadRequest, "ROLE_CYCLE", "role cannot inherit from itself, directly or through its parents")
+	ErrUnknownRole          = httputil.NewCodedError(httputil.ErrBadRequest, "UNKNOWN_ROLE", "parent role does not exist")
+	ErrRegistrationDisabled = httputil.NewCodedError(httputil.ErrForbidden, "REGISTRATION_DISABLED", "registration is by invitation only")
+	ErrEmailTaken           = httputil.NewCodedError(httputil.ErrConflict, "EMAIL_TAKEN", "an account with this email already exists")
+
+	ErrImpersonateSelf         = httputil.NewCodedError(httputil.ErrBadRequest, "CANNOT_IMPERSONATE_SELF", "you cannot impersonate yourself")
+	ErrImpersonationEscalation = httputil.NewCodedError(httputil.ErrForbidden, "IMPERSONATION_NOT_ALLOWED", "the user holds permissions you do not have")
+	ErrImpersonationRestricted = httputil.NewCodedError(httputil.ErrForbidden, "IMPERSONATION_RESTRICTED", "this endpoint is not available while impersonating")
+	ErrNotImpersonating        = httputil.NewCodedError(httputil.ErrBadRequest, "NOT_IMPERSONATING", "this session is not an impersonation")
+
+	ErrOIDCNotConfigured    = httputil.NewCodedError(httputil.ErrNotFound, "OIDC_NOT_CONFIGURED", "single sign-on is not configured")
+	ErrOIDCLoginFailed      = httputil.NewCodedError(httputil.ErrUnauthorized, "OIDC_LOGIN_FAILED", "the identity provider did not confirm the login")
+	ErrOIDCStateMismatch    = httputil.NewCodedError(httputil.ErrUnauthorized, "OIDC_STATE_MISMATCH", "single sign-on was not started in this browser")
+	ErrOIDCEmailNotVerified = httputil.NewCodedError(httputil.ErrForbidden, "OIDC_EMAIL_NOT_VERIFIED", "the identity provider has not verified this email address")
+	ErrOIDCAccountNotFound  = httputil.NewCodedError(httputil.ErrForbidden, "OIDC_ACCOUNT_NOT_FOUND", "no staff account matches this identity")
+	ErrOIDCIdentityConflict = httputil.NewCodedError(httputil.ErrConflict, "OIDC_IDENTITY_CONFLICT", "this account is already linked to another identity")
+)
+
+// LoginThrottledError is returned while failed logins are being slowed down or locked out.
+// It wraps ErrTooManyAttempts or ErrLoginLocked; RetryAfter tells the client when to try again.
+type LoginThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string { return e.Err.Error() }
+
+func (e *LoginThrottledError) Unwrap() error { return e.Err }
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,14 +11,98 @@ import (
 type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	CreateUser(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	RehashPassword(ctx context.Context, userID uuid.UUID, currentHash, newHash string) error
+	ActivateUser(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	ListUsers(ctx context.Context, filter UserFilter) ([]User, int, error)
+	UpdateUserProfile(ctx context.Context, user *User) error
+	ArchiveUser(ctx context.Context, id uuid.UUID) (bool, error)
+	RestoreUser(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// Single-use user tokens
+	CreateUserToken(ctx context.Context, token *UserToken) error
+	GetUserToken(ctx context.Context, purpose, tokenHash string) (*UserToken, error)
+	ConsumeUserToken(ctx context.Context, purpose, tokenHash string) (*UserToken, error)
+	InvalidateUserTokens(ctx context.Context, userID uuid.UUID, purpose string) error
+
+	// Sessions
+	CreateSession(ctx context.Context, session *Session, token *RefreshToken) error
+	GetSession(ctx context.Context, id uuid.UUID) (*Session, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedID uuid.UUID, next *RefreshToken) error
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	IsSessionActive(ctx context.Context, id uuid.UUID) (bool, error)
+	RevokeSession(ctx context.Context, id uuid.UUID) error
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeOtherUserSessions(ctx context.Context, userID, keepID uuid.UUID) error
+	AddSessionAMR(ctx context.Context, sessionID uuid.UUID, method string) error
+
+	// Invitations
+	CreateInvitation(ctx context.Context, invitation *Invitation) error
+	ListPendingInvitations(ctx context.Context) ([]Invitation, error)
+	GetPendingInvitation(ctx context.Context, tokenHash string) (*Invitation, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) (*Invitation, error)
+	AcceptInvitation(ctx context.Context, tokenHash string, user *User) ([]int, error)
+
+	// Single sign-on
+	CreateOIDCState(ctx context.Context, state *OIDCState) error
+	ConsumeOIDCState(ctx context.Context, stateHash string) (*OIDCState, error)
+	GetUserIdentity(ctx context.Context, issuer, subject string) (*UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity *UserIdentity) error
+	TouchUserIdentity(ctx context.Context, id uuid.UUID, email string) error
+
+	// API keys
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	GetActiveAPIKey(ctx context.Context, keyHash string) (*APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error
+
+	// Failed login throttling
+	CountLoginAttempt(ctx context.Context, scope, key string, resetBefore time.Time, admit func(*LoginThrottle) error) (*LoginThrottle, error)
+	ReleaseLoginAttempt(ctx context.Context, scope, key string, countedAt, previousAt time.Time) error
+	LockLogin(ctx context.Context, scope, key string, until time.Time) (bool, error)
+	ClearLoginThrottle(ctx context.Context, scope, key string) error
+
+	// Two-factor authentication
+	GetUserMFA(ctx context.Context, userID uuid.UUID) (*UserMFA, error)
+	SavePendingMFA(ctx context.Context, userID uuid.UUID, secret string) error
+	ConfirmMFA(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	AdvanceMFAStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	DeleteUserMFA(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+	UserRequiresMFA(ctx context.Context, userID uuid.UUID) (bool, error)
+	SetRoleRequireMFA(ctx context.Context, roleID int, required bool) error
 
 	// RBAC
-	UpsertPermissions(ctx context.Context, permissions []Permission) error
+	SyncModulePermissions(ctx context.Context, module string, permissions []Permission) (deprecated []string, err error)
+	GetPermissions(ctx context.Context) ([]Permission, error)
+	GetPermissionIDs(ctx context.Context) ([]string, error)
+	GetDeprecatedPermissions(ctx context.Context) ([]DeprecatedPermission, error)
+	PurgeDeprecatedPermissions(ctx context.Context) (purged []string, grants []RoleGrant, err error)
 	CreateRole(ctx context.Context, name string) (*Role, error)
 	GetRoles(ctx context.Context) ([]Role, error)
-	AssignRoleToUser(ctx context.Context, userID uuid.UUID, roleID int) error
+	GetRole(ctx context.Context, id int) (*Role, error)
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	GetRolePermissions(ctx context.Context, roleID int) ([]string, error)
+	GetRoleMembers(ctx context.Context, roleID int) ([]User, error)
+	GetRoleParents(ctx context.Context, roleID int) ([]Role, error)
+	GetRoleInheritedPermissions(ctx context.Context, roleID int) ([]string, error)
+	SetRoleParents(ctx context.Context, roleID int, parentIDs []int) error
+	RenameRole(ctx context.Context, id int, name string) (*Role, error)
+	DeleteRole(ctx context.Context, id int) error
+	AssignRoleToUser(ctx context.Context, userID uuid.UUID, roleID int) (bool, error)
+	UnassignRoleFromUser(ctx context.Context, userID uuid.UUID, roleID int) error
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]Role, error)
 	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
-	AddPermissionToRole(ctx context.Context, roleID int, permissionID string) error
+	GetUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]int, error)
+	AddPermissionToRole(ctx context.Context, roleID int, permissionID string) (bool, error)
+	RemovePermissionFromRole(ctx context.Context, roleID int, permissionID string) error
+	SetRolePermissions(ctx context.Context, roleID int, permissionIDs []string) (added, removed []string, err error)
 
 	// Menu definitions
 	UpsertMenuDefinitions(ctx context.Context, defs []MenuDefinition) error
@@ -26,15 +111,81 @@ type Repository interface {
 
 // Service defines an interface for managing user authentication and registration operations in the system.
 type Service interface {
-	Login(ctx context.Context, email, password string) (string, error)
+	Login(ctx context.Context, email, password string, meta SessionMeta) (*LoginResult, error)
+	CompleteMFALogin(ctx context.Context, challengeToken, code string, meta SessionMeta) (*TokenPair, error)
+	StartOIDCLogin(ctx context.Context) (*OIDCAuthorization, error)
+	CompleteOIDCLogin(ctx context.Context, code, state, browserState string, meta SessionMeta) (*LoginResult, error)
 	Register(ctx context.Context, user User) error
+	AcceptInvitation(ctx context.Context, input InvitationAcceptance) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string) error
+	ConfirmEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+
+	// Self-service profile
+	GetMe(ctx context.Context, userID uuid.UUID) (*Me, error)
+	UpdateMe(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*User, error)
+
+	// User administration
+	ListUsers(ctx context.Context, filter UserFilter) ([]User, int, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, update UserUpdate) (*User, error)
+	ArchiveUser(ctx context.Context, actorID, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) error
+	UnlockUser(ctx context.Context, id uuid.UUID) error
+	BootstrapAdmin(ctx context.Context, input BootstrapAdmin) (*BootstrapAdminResult, error)
+
+	// Invitations
+	CreateInvitation(ctx context.Context, actorID uuid.UUID, input NewInvitation) (*Invitation, error)
+	ListInvitations(ctx context.Context) ([]Invitation, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) error
+
+	// Sessions
+	Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ValidateAccessToken(ctx context.Context, token string) (*UserClaims, error)
+	JWKS() JSONWebKeySet
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+
+	// Impersonation
+	StartImpersonation(ctx context.Context, actorID, actorSessionID, userID uuid.UUID, meta SessionMeta) (*TokenPair, error)
+	StopImpersonation(ctx context.Context, claims *UserClaims) error
+
+	// API keys
+	CreateAPIKey(ctx context.Context, userID uuid.UUID, input NewAPIKey) (*APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error
+	ValidateAPIKey(ctx context.Context, key string) (*UserClaims, error)
+
+	// Two-factor authentication
+	GetMFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatus, error)
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (*TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID, sessionID uuid.UUID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	SetRoleRequireMFA(ctx context.Context, roleID int, required bool) error
 
 	// RBAC
-	RegisterModulePermissions(ctx context.Context, module string, permissions []string) error
+	RegisterModulePermissions(ctx context.Context, module string, permissions []PermissionDefinition) error
+	GetPermissionCatalogue(ctx context.Context) ([]PermissionModule, error)
+	GetDeprecatedPermissions(ctx context.Context) ([]DeprecatedPermission, error)
+	PurgeDeprecatedPermissions(ctx context.Context) ([]string, error)
 	RegisterModuleMenus(ctx context.Context, domain string, defs []MenuDefinition) error
 	CreateRole(ctx context.Context, name string) (*Role, error)
 	GetRoles(ctx context.Context) ([]Role, error)
+	GetRoleDetail(ctx context.Context, id int) (*RoleDetail, error)
+	RenameRole(ctx context.Context, id int, name string) (*Role, error)
+	DeleteRole(ctx context.Context, id int) error
+	SetRoleParents(ctx context.Context, roleID int, parentIDs []int) ([]Role, error)
 	AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error
+	UnassignRole(ctx context.Context, userID uuid.UUID, roleID int) error
 	GetMyMenu(ctx context.Context, userID uuid.UUID) ([]MenuNode, error)
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+	GetUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]int, error)
+	ApplyCacheInvalidation(inv CacheInvalidation)
 	AddPermissionToRole(ctx context.Context, roleID int, permissionID string) error
+	RemovePermissionFromRole(ctx context.Context, roleID int, permissionID string) error
+	SetRolePermissions(ctx context.Context, roleID int, permissionIDs []string) (added, removed []string, err error)
 }
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation onboards a staff member when open registration is disabled. The token is mailed to
+// Email and only its hash is stored; accepting it creates an activated account holding RoleIDs.
+type Invitation struct {
+	ID        uuid.UUID  `json:"id"`
+	Email     string     `json:"email"`
+	FullName  string     `json:"full_name"`
+	RoleIDs   []int      `json:"role_ids"`
+	TokenHash string     `json:"-"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// NewInvitation holds the fields an administrator fills in. FullName is a suggestion the invitee
+// may change when accepting.
+type NewInvitation struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	RoleIDs  []int  `json:"role_ids"`
+}
+
+// InvitationAcceptance is what the invitee submits. An empty FullName keeps the invitation's.
+type InvitationAcceptance struct {
+	Token    string `json:"token"`
+	FullName string `json:"full_name"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// JSONWebKey is the public part of a signing key, as published in the JWKS document (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet lists every key a token may currently be verified with.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCAuthorization starts a single sign-on login: the client sends the user to URL, and posts the
+// code and state the provider redirects back with before ExpiresIn seconds have passed.
+type OIDCAuthorization struct {
+	URL       string `json:"authorization_url"`
+	ExpiresIn int    `json:"expires_in"`
+	// State is kept by the browser that starts the login, so the callback can prove it comes from it.
+	State string `json:"-"`
+}
+
+// OIDCState is a pending single sign-on login. The state travels through the browser, so only its
+// hash is stored; the PKCE verifier and nonce never leave the server.
+type OIDCState struct {
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+// UserIdentity links a user to their account at the OpenID provider. Once linked, logins match on
+// issuer and subject, so a later email change at the provider keeps the link.
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
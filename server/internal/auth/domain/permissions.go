@@ -1,19 +1,35 @@
 package domain
 
+import "github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+
+type PermissionDefinition = authz.PermissionDefinition
+
 const (
 	PermissionRoleRead   = "auth.role.read"
 	PermissionRoleWrite  = "auth.role.write"
 	PermissionRoleDelete = "auth.role.delete"
 	PermissionUserRead   = "auth.user.read"
 	PermissionUserWrite  = "auth.user.write"
+
+	PermissionUserImpersonate = "auth.user.impersonate"
 )
 
-func GetAvailablePermissions() []string {
-	return []string{
-		PermissionRoleRead,
-		PermissionRoleWrite,
-		PermissionRoleDelete,
-		PermissionUserRead,
-		PermissionUserWrite,
+// ImpersonationDenied lists the permissions an impersonation session never exercises, whatever the
+// impersonated user holds, so support staff cannot change roles or accounts while acting as someone.
+var ImpersonationDenied = []string{
+	PermissionRoleWrite,
+	PermissionRoleDelete,
+	PermissionUserWrite,
+	PermissionUserImpersonate,
+}
+
+func GetAvailablePermissions() []PermissionDefinition {
+	return []PermissionDefinition{
+		{ID: PermissionRoleRead, Description: "View roles, their permissions and members", Group: "Roles", Order: 10},
+		{ID: PermissionRoleWrite, Description: "Create and edit roles and their permissions", Group: "Roles", Order: 20},
+		{ID: PermissionRoleDelete, Description: "Delete roles", Group: "Roles", Order: 30},
+		{ID: PermissionUserRead, Description: "View staff accounts", Group: "Users", Order: 40},
+		{ID: PermissionUserWrite, Description: "Edit, archive, restore and unlock staff accounts", Group: "Users", Order: 50},
+		{ID: PermissionUserImpersonate, Description: "Sign in as another staff account for support", Group: "Users", Order: 60},
 	}
 }
@@ -0,0 +1,35 @@
+package domain
+
+// RoleCyclePath reports whether making parents the parents of roleID would create an inheritance cycle.
+// edges maps each role to its current parents; the existing parents of roleID are ignored because they
+// are being replaced. The returned path starts and ends at roleID.
+func RoleCyclePath(edges map[int][]int, roleID int, parents []int) ([]int, bool) {
+	visited := make(map[int]bool)
+	var path []int
+
+	var walk func(id int) bool
+	walk = func(id int) bool {
+		path = append(path, id)
+		if id == roleID {
+			return true
+		}
+		if !visited[id] {
+			visited[id] = true
+			for _, parent := range edges[id] {
+				if walk(parent) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	for _, parent := range parents {
+		path = []int{roleID}
+		if walk(parent) {
+			return path, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleCyclePath(t *testing.T) {
+	// 3 -> 2 -> 1, 4 -> 1
+	edges := map[int][]int{3: {2}, 2: {1}, 4: {1}}
+
+	tests := []struct {
+		name    string
+		roleID  int
+		parents []int
+		want    []int
+	}{
+		{"no parents", 1, nil, nil},
+		{"self", 1, []int{1}, []int{1, 1}},
+		{"direct", 2, []int{3}, []int{2, 3, 2}},
+		{"transitive", 1, []int{3}, []int{1, 3, 2, 1}},
+		{"second parent", 1, []int{5, 4}, []int{1, 4, 1}},
+		{"sibling", 4, []int{3}, nil},
+		{"replacing existing parents", 2, []int{4}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := RoleCyclePath(edges, tt.roleID, tt.parents)
+			if ok != (tt.want != nil) || !reflect.DeepEqual(path, tt.want) {
+				t.Fatalf("expected %v, got %v (cycle %v)", tt.want, path, ok)
+			}
+		})
+	}
+}
@@ -8,25 +8,260 @@ import (
 
 // User represents an entity with personal and account-related data managed within the system.
 type User struct {
-	ID           uuid.UUID
-	Email        string
-	PasswordHash string
-	FullName     string
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	FullName     string    `json:"full_name"`
 
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	ActivatedAt *time.Time
-	ArchivedAt  *time.Time
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ActivatedAt *time.Time `json:"activated_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+}
+
+// User list status filters.
+const (
+	UserStatusActive   = "active"
+	UserStatusArchived = "archived"
+	UserStatusAll      = "all"
+)
+
+// UserFilter narrows down the user listing. Search matches email and full name.
+type UserFilter struct {
+	Search string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// Me is the signed-in user. Roles are the ones assigned directly; Permissions are the effective
+// grants, inherited ones and wildcards included. Scopes is only set for API keys and further limits
+// Permissions.
+type Me struct {
+	User
+	Roles       []Role   `json:"roles"`
+	Permissions []string `json:"permissions"`
+	Scopes      []string `json:"scopes,omitempty"`
+	MFAPending  bool     `json:"mfa_pending"`
+	// ImpersonatorID is set while a staff member is acting as this user.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+}
+
+// ProfileUpdate holds the fields users may change on their own account. Nil fields are left untouched.
+// Email changes go through an administrator.
+type ProfileUpdate struct {
+	FullName *string `json:"full_name"`
+}
+
+// UserUpdate holds the profile fields an administrator may change. Nil fields are left untouched.
+type UserUpdate struct {
+	Email    *string `json:"email"`
+	FullName *string `json:"full_name"`
+}
+
+// AdministratorRole is the role maintained by the admin bootstrap command. It holds the "*" grant.
+const AdministratorRole = "Administrator"
+
+// BootstrapAdmin describes the administrator account ensured by the admin bootstrap command.
+// Password is only used when the account does not exist yet.
+type BootstrapAdmin struct {
+	Email    string
+	FullName string
+	Password string
+}
+
+// BootstrapAdminResult reports what the bootstrap changed, so repeated runs can be told apart.
+type BootstrapAdminResult struct {
+	UserID      uuid.UUID
+	RoleID      int
+	UserCreated bool
+	RoleCreated bool
+}
+
+// CacheInvalidation names the cached access data every API replica must drop after a change.
+type CacheInvalidation struct {
+	UserIDs  []uuid.UUID // Users whose roles changed
+	AllUsers bool        // Role grants or inheritance changed, which may affect anyone
+	Menus    bool        // Menu definitions changed
 }
 
 type Permission struct {
-	ID          string    `json:"id"`
-	Module      string    `json:"module"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID           string     `json:"id"`
+	Module       string     `json:"module"`
+	Description  string     `json:"description"`
+	Group        string     `json:"group"`
+	Order        int        `json:"order"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+}
+
+// DeprecatedPermission is a permission its module no longer declares, with the roles still granting it.
+type DeprecatedPermission struct {
+	Permission
+	Roles []Role `json:"roles"`
+}
+
+// RoleGrant is a single permission granted to a role.
+type RoleGrant struct {
+	RoleID       int
+	PermissionID string
+}
+
+// PermissionModule is one section of the permission catalogue.
+type PermissionModule struct {
+	Module      string       `json:"module"`
+	Permissions []Permission `json:"permissions"`
 }
 
 type Role struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	RequireMFA bool   `json:"require_mfa"`
+}
+
+// RoleDetail is a role together with the permissions it grants and the users holding it.
+// Permissions are granted directly; InheritedPermissions come from the parents and their ancestors.
+type RoleDetail struct {
+	Role
+	Parents              []Role   `json:"parents"`
+	Permissions          []string `json:"permissions"`
+	InheritedPermissions []string `json:"inherited_permissions"`
+	Members              []User   `json:"members"`
+}
+
+// Session represents a signed-in device. All refresh tokens issued for the same login share a session,
+// so revoking the session invalidates the whole token family.
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	AMR        []string   `json:"amr"`
+	Current    bool       `json:"current"`
+	// ImpersonatorID is set on sessions opened by a staff member acting as the user.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+}
+
+// RefreshToken is a single-use token belonging to a session. Only its hash is ever stored.
+type RefreshToken struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// SessionMeta describes the client a session is issued to.
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Authentication method references (RFC 8176) recorded on sessions and access tokens.
+// AMRFederated is not registered by the RFC; it marks sessions opened through the OpenID provider.
+const (
+	AMRPassword  = "pwd"
+	AMRMFA       = "mfa"
+	AMRFederated = "fed"
+)
+
+// TokenPair is the result of a successful login or refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+	SessionID    uuid.UUID
+	MFAPending   bool
+}
+
+// Purposes of single-use user tokens.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token mailed to a user. Only its hash is ever stored.
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// LoginResult is the outcome of the first login step. Users with two-factor authentication enabled
+// receive a short-lived challenge token instead of a token pair and finish with CompleteMFALogin.
+type LoginResult struct {
+	Tokens         *TokenPair
+	ChallengeToken string
+	ChallengeTTL   int
+}
+
+// UserMFA is the TOTP factor of a user. It is pending until ConfirmedAt is set.
+type UserMFA struct {
+	UserID       uuid.UUID
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// MFAStatus summarises the second factor of the signed-in user.
+type MFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment carries the shared secret shown to the user while enrolling an authenticator app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// Scopes of failed login counters.
+const (
+	ThrottleScopeAccount = "account"
+	ThrottleScopeIP      = "ip"
+)
+
+// LoginThrottle counts recent failed logins for an account or a client IP.
+type LoginThrottle struct {
+	Scope         string
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// APIKeyPrefix starts every API key so the middleware can tell keys and JWTs apart.
+const APIKeyPrefix = "tfs_"
+
+// APIKey lets scripts call the API on behalf of a user with a subset of the user's permissions.
+// Only the hash of the key is stored; Prefix is the visible part used to recognise it.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKey holds the caller-supplied fields of a new API key. A nil ExpiresAt uses the default lifetime.
+type NewAPIKey struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
@@ -16,7 +16,7 @@ var MenuDefinitions = []domain.MenuDefinition{
 		Label:       "System",
 		Icon:        "settings",
 		Order:       90,
-		Permissions: []string{domain.PermissionRoleRead},
+		Permissions: []string{domain.PermissionRoleRead, domain.PermissionUserRead},
 		Visible:     true,
 	},
 	{
@@ -28,4 +28,13 @@ var MenuDefinitions = []domain.MenuDefinition{
 		Permissions: []string{domain.PermissionRoleRead},
 		Visible:     true,
 	},
+	{
+		ID:          "auth:users",
+		Label:       "Users",
+		Path:        "/system/users",
+		Order:       20,
+		ParentID:    "auth:system",
+		Permissions: []string{domain.PermissionUserRead},
+		Visible:     true,
+	},
 }
@@ -2,6 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,15 +15,116 @@ import (
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/repositories"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/service"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/mail"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/password"
 )
 
 type AuthModule struct {
-	Service domain.Service
+	Service    domain.Service
+	Guard      authz.Guard
+	Authorizer authz.Authorizer
+
+	cookies http.CookieConfig
 }
 
-func NewModule(pool *pgxpool.Pool, nc *nats.Conn, jwtSecret string) *AuthModule {
+// NewModule wires the auth module. recorder receives the logins, which publish no event.
+func NewModule(pool *pgxpool.Pool, nc *nats.Conn, cfg *platform.Config, recorder audit.Recorder) (*AuthModule, error) {
+	keys, err := service.LoadKeyRing(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	mailer, err := mail.NewSender(mail.Config{
+		Transport:    cfg.MailTransport,
+		From:         cfg.MailFrom,
+		FileDir:      cfg.MailFileDir,
+		SMTPAddr:     cfg.MailSMTPAddr,
+		SMTPUsername: cfg.MailSMTPUsername,
+		SMTPPassword: cfg.MailSMTPPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm:  cfg.PasswordHashAlgorithm,
+		BcryptCost: cfg.PasswordBcryptCost,
+		Argon2id: password.Argon2id{
+			Memory:      cfg.PasswordArgon2Memory,
+			Iterations:  cfg.PasswordArgon2Iterations,
+			Parallelism: cfg.PasswordArgon2Parallelism,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := cookieConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider *oidc.Provider
+	if cfg.OIDCIssuerURL != "" {
+		provider, err = oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+			GroupsClaim:  cfg.OIDCGroupsClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	repo := repositories.NewPgxRepository(pool)
-	svc := service.NewAuthService(repo, nc, jwtSecret)
+	svc := service.NewAuthService(repo, nc, service.Config{
+		Keys:              keys,
+		AccessTokenTTL:    cfg.AccessTokenTTL,
+		RefreshTokenTTL:   cfg.RefreshTokenTTL,
+		Mailer:            mailer,
+		AppURL:            cfg.AppURL,
+		PasswordResetTTL:  cfg.PasswordResetTTL,
+		VerificationTTL:   cfg.VerificationTTL,
+		InvitationTTL:     cfg.InvitationTTL,
+		AllowRegistration: cfg.AllowRegistration,
+		MFAIssuer:         cfg.MFAIssuer,
+		MFAChallengeTTL:   cfg.MFAChallengeTTL,
+		AccountThrottle: service.ThrottlePolicy{
+			FreeAttempts:    cfg.LoginAccountFreeAttempts,
+			MaxFailures:     cfg.LoginAccountMaxFailures,
+			BaseDelay:       cfg.LoginBaseDelay,
+			MaxDelay:        cfg.LoginMaxDelay,
+			LockoutDuration: cfg.LoginLockoutDuration,
+		},
+		IPThrottle: service.ThrottlePolicy{
+			FreeAttempts:    cfg.LoginIPFreeAttempts,
+			MaxFailures:     cfg.LoginIPMaxFailures,
+			BaseDelay:       cfg.LoginBaseDelay,
+			MaxDelay:        cfg.LoginMaxDelay,
+			LockoutDuration: cfg.LoginLockoutDuration,
+		},
+		PasswordPolicy: password.Policy{
+			MinLength:     cfg.PasswordMinLength,
+			MaxBytes:      cfg.PasswordMaxBytes,
+			CheckBreached: cfg.PasswordCheckBreached,
+		},
+		Hasher:           hasher,
+		APIKeyDefaultTTL: cfg.APIKeyDefaultTTL,
+		APIKeyMaxTTL:     cfg.APIKeyMaxTTL,
+		AccessCacheTTL:   cfg.AccessCacheTTL,
+		ImpersonationTTL: cfg.ImpersonationTTL,
+		Audit:            recorder,
+		OIDC:             provider,
+		OIDCStateTTL:     cfg.OIDCStateTTL,
+		OIDCGroupRoles:   cfg.OIDCGroupRoles,
+	})
 
 	events.RegisterListeners(nc, svc)
 
@@ -30,13 +135,42 @@ func NewModule(pool *pgxpool.Pool, nc *nats.Conn, jwtSecret string) *AuthModule
 		_ = svc.RegisterModuleMenus(context.Background(), "auth", MenuDefinitions)
 	}()
 
-	return &AuthModule{Service: svc}
+	guard := http.NewPermissionGuard(svc)
+	return &AuthModule{Service: svc, Guard: guard, Authorizer: guard, cookies: cookies}, nil
 }
 
 func (m *AuthModule) RegisterRoutes(r *chi.Mux) {
-	http.RegisterHTTPHandlers(r, m.Service)
+	http.RegisterHTTPHandlers(r, m.Service, m.cookies)
 }
 
 func (m *AuthModule) RegisterProtectedRoutes(r chi.Router) {
-	http.RegisterProtectedHTTPHandlers(r, m.Service)
+	http.RegisterProtectedHTTPHandlers(r, m.Service, m.Guard)
+}
+
+// Middleware returns the authentication middleware for protected route groups.
+func (m *AuthModule) Middleware() func(next nethttp.Handler) nethttp.Handler {
+	return http.AuthMiddleware(m.Service, m.cookies)
+}
+
+func cookieConfig(cfg *platform.Config) (http.CookieConfig, error) {
+	cookies := http.CookieConfig{
+		Enabled:         cfg.SessionCookies,
+		Domain:          cfg.SessionCookieDomain,
+		Secure:          cfg.SessionCookieSecure,
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+	}
+	switch strings.ToLower(cfg.SessionCookieSameSite) {
+	case "strict":
+		cookies.SameSite = nethttp.SameSiteStrictMode
+	case "lax":
+		cookies.SameSite = nethttp.SameSiteLaxMode
+	case "none":
+		if !cookies.Secure {
+			return cookies, errors.New("SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE")
+		}
+		cookies.SameSite = nethttp.SameSiteNoneMode
+	default:
+		return cookies, fmt.Errorf("invalid SESSION_COOKIE_SAMESITE %q, want strict, lax or none", cfg.SessionCookieSameSite)
+	}
+	return cookies, nil
 }
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const apiKeyColumns = `k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.created_at, k.expires_at, k.last_used_at, k.revoked_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.Scopes, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *pgxRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	err := r.pool.QueryRow(ctx, query, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.ExpiresAt).
+		Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("auth repo create api key: %w", err)
+	}
+	return nil
+}
+
+// GetActiveAPIKey returns the key matching the hash when it is neither revoked nor expired
+// and its owner is not archived. Anything else yields httputil.ErrNotFound.
+func (r *pgxRepo) GetActiveAPIKey(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND k.expires_at > now() AND u.archived_at IS NULL
+	`
+	k, err := scanAPIKey(r.pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get active api key: %w", err)
+	}
+	return k, nil
+}
+
+// TouchAPIKey records usage. Writes are limited to one per minute per key so busy scripts
+// do not turn every request into an UPDATE.
+func (r *pgxRepo) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("auth repo touch api key: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepo) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys k
+		WHERE k.user_id = $1 AND k.revoked_at IS NULL
+		ORDER BY k.created_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, nil
+}
+
+func (r *pgxRepo) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("auth repo revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
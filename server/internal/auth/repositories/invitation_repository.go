@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const invitationColumns = `
+	i.id, i.email, i.full_name, i.token_hash, i.invited_by, i.created_at, i.expires_at,
+	COALESCE((SELECT array_agg(ir.role_id ORDER BY ir.role_id) FROM user_invitation_roles ir WHERE ir.invitation_id = i.id), '{}')
+`
+
+const pendingInvitation = `i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > now()`
+
+func scanInvitation(row pgx.Row) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := row.Scan(&inv.ID, &inv.Email, &inv.FullName, &inv.TokenHash, &inv.InvitedBy, &inv.CreatedAt, &inv.ExpiresAt, &inv.RoleIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// CreateInvitation stores the invitation with its roles and revokes any other pending invitation for
+// the same email, so only the latest link works.
+func (r *pgxRepo) CreateInvitation(ctx context.Context, inv *domain.Invitation) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo create invitation: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	revoke := `UPDATE user_invitations i SET revoked_at = now() WHERE lower(i.email) = lower($1) AND ` + pendingInvitation
+	if _, err := tx.Exec(ctx, revoke, inv.Email); err != nil {
+		return fmt.Errorf("auth repo create invitation: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_invitations (id, email, full_name, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+	err = tx.QueryRow(ctx, query, inv.ID, inv.Email, inv.FullName, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt).
+		Scan(&inv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("auth repo create invitation: %w", err)
+	}
+
+	roles := `INSERT INTO user_invitation_roles (invitation_id, role_id) SELECT $1, unnest($2::int[])`
+	if _, err := tx.Exec(ctx, roles, inv.ID, inv.RoleIDs); err != nil {
+		if isForeignKeyViolation(err) {
+			return httputil.ErrNotFound
+		}
+		return fmt.Errorf("auth repo create invitation: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepo) ListPendingInvitations(ctx context.Context) ([]domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations i WHERE ` + pendingInvitation + ` ORDER BY i.created_at DESC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []domain.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+// GetPendingInvitation returns the invitation matching the hash. Accepted, revoked and expired
+// invitations yield httputil.ErrNotFound.
+func (r *pgxRepo) GetPendingInvitation(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations i WHERE i.token_hash = $1 AND ` + pendingInvitation
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// RevokeInvitation returns the revoked invitation, or httputil.ErrNotFound when it is not pending.
+func (r *pgxRepo) RevokeInvitation(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	query := `
+		UPDATE user_invitations i SET revoked_at = now()
+		WHERE i.id = $1 AND ` + pendingInvitation + `
+		RETURNING ` + invitationColumns
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo revoke invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// AcceptInvitation creates the user, grants the invitation's roles and marks it accepted in one
+// transaction. It returns the granted role IDs. A token that is no longer pending yields
+// httputil.ErrNotFound and an email already in use httputil.ErrConflict; nothing is written then.
+func (r *pgxRepo) AcceptInvitation(ctx context.Context, tokenHash string, user *domain.User) ([]int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	insertUser := `INSERT INTO users (id, email, password_hash, full_name, activated_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, insertUser, user.ID, user.Email, user.PasswordHash, user.FullName, user.ActivatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, httputil.ErrConflict
+		}
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+
+	var invitationID uuid.UUID
+	accept := `
+		UPDATE user_invitations i SET accepted_at = now(), accepted_user_id = $2
+		WHERE i.token_hash = $1 AND ` + pendingInvitation + `
+		RETURNING i.id
+	`
+	if err := tx.QueryRow(ctx, accept, tokenHash, user.ID).Scan(&invitationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+
+	grant := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, role_id FROM user_invitation_roles WHERE invitation_id = $2
+		RETURNING role_id
+	`
+	rows, err := tx.Query(ctx, grant, user.ID, invitationID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+	roleIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("auth repo accept invitation: %w", err)
+	}
+	return roleIDs, nil
+}
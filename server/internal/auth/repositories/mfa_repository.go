@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (r *pgxRepo) GetUserMFA(ctx context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_mfa WHERE user_id = $1`
+	var m domain.UserMFA
+	err := r.pool.QueryRow(ctx, query, userID).
+		Scan(&m.UserID, &m.Secret, &m.ConfirmedAt, &m.LastUsedStep, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get user mfa: %w", err)
+	}
+	return &m, nil
+}
+
+// SavePendingMFA stores a new unconfirmed secret, replacing any previous pending one.
+// It returns httputil.ErrConflict when the user already has a confirmed factor.
+func (r *pgxRepo) SavePendingMFA(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+		WHERE user_mfa.confirmed_at IS NULL
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("auth repo save pending mfa: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrConflict
+	}
+	return nil
+}
+
+// ConfirmMFA enables the pending factor and stores its first set of recovery codes in one transaction.
+func (r *pgxRepo) ConfirmMFA(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo confirm mfa: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `UPDATE user_mfa SET confirmed_at = now(), last_used_step = $2 WHERE user_id = $1 AND confirmed_at IS NULL`
+	tag, err := tx.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("auth repo confirm mfa: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrConflict
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AdvanceMFAStep records step as the last used TOTP window. It returns false when the step is not
+// newer than the stored one, i.e. the code was already used.
+func (r *pgxRepo) AdvanceMFAStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	tag, err := r.pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("auth repo advance mfa step: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepo) DeleteUserMFA(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo delete user mfa: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("auth repo delete user mfa: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("auth repo delete user mfa: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo replace recovery codes: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("auth repo replace recovery codes: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, h := range codeHashes {
+		batch.Queue(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("auth repo replace recovery codes: %w", err)
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode marks a matching unused code as used and reports whether one was found.
+func (r *pgxRepo) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = now() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("auth repo consume recovery code: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepo) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT count(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	var n int
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("auth repo count recovery codes: %w", err)
+	}
+	return n, nil
+}
+
+// UserRequiresMFA reports whether one of the user's roles, or a role they inherit from, requires
+// two-factor authentication.
+func (r *pgxRepo) UserRequiresMFA(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE effective_roles(role_id) AS (
+			SELECT role_id FROM user_roles WHERE user_id = $1
+			UNION
+			SELECT rp.parent_id FROM role_parents rp JOIN effective_roles er ON rp.role_id = er.role_id
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM effective_roles er JOIN roles ro ON ro.id = er.role_id
+			WHERE ro.require_mfa
+		)
+	`
+	var required bool
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&required); err != nil {
+		return false, fmt.Errorf("auth repo user requires mfa: %w", err)
+	}
+	return required, nil
+}
+
+func (r *pgxRepo) SetRoleRequireMFA(ctx context.Context, roleID int, required bool) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE roles SET require_mfa = $2 WHERE id = $1`, roleID, required)
+	if err != nil {
+		return fmt.Errorf("auth repo set role require mfa: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// CreateOIDCState stores a pending login and clears the ones that expired without being used.
+func (r *pgxRepo) CreateOIDCState(ctx context.Context, state *domain.OIDCState) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at <= now()`); err != nil {
+		return fmt.Errorf("auth repo prune oidc states: %w", err)
+	}
+
+	query := `INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, expires_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.pool.Exec(ctx, query, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("auth repo create oidc state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOIDCState deletes a pending login and returns it. Unknown, expired and already used states
+// yield httputil.ErrNotFound.
+func (r *pgxRepo) ConsumeOIDCState(ctx context.Context, stateHash string) (*domain.OIDCState, error) {
+	query := `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND expires_at > now()
+		RETURNING state_hash, code_verifier, nonce, expires_at
+	`
+	var s domain.OIDCState
+	err := r.pool.QueryRow(ctx, query, stateHash).Scan(&s.StateHash, &s.CodeVerifier, &s.Nonce, &s.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo consume oidc state: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *pgxRepo) GetUserIdentity(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, issuer, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+	var i domain.UserIdentity
+	err := r.pool.QueryRow(ctx, query, issuer, subject).
+		Scan(&i.ID, &i.UserID, &i.Issuer, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get user identity: %w", err)
+	}
+	return &i, nil
+}
+
+// CreateUserIdentity links a user to a provider account. A user already linked to another account at
+// the same issuer, or an account already linked to someone, yields httputil.ErrConflict.
+func (r *pgxRepo) CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		RETURNING created_at, last_login_at
+	`
+	err := r.pool.QueryRow(ctx, query, identity.ID, identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+		Scan(&identity.CreatedAt, &identity.LastLoginAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return httputil.ErrConflict
+		}
+		return fmt.Errorf("auth repo create user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepo) TouchUserIdentity(ctx context.Context, id uuid.UUID, email string) error {
+	query := `UPDATE user_identities SET email = $2, last_login_at = now() WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, id, email); err != nil {
+		return fmt.Errorf("auth repo touch user identity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+// SyncModulePermissions stores the full permission declaration of a module. Permissions of the module
+// that are not part of it are marked deprecated; the IDs deprecated by this call are returned.
+func (r *pgxRepo) SyncModulePermissions(ctx context.Context, module string, permissions []domain.Permission) ([]string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo sync permissions: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ids := make([]string, 0, len(permissions))
+	batch := &pgx.Batch{}
+	for _, p := range permissions {
+		ids = append(ids, p.ID)
+		batch.Queue(`
+			INSERT INTO permissions (id, module, description, group_name, sort_order)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO UPDATE SET
+				module = EXCLUDED.module,
+				description = EXCLUDED.description,
+				group_name = EXCLUDED.group_name,
+				sort_order = EXCLUDED.sort_order,
+				deprecated_at = NULL`,
+			p.ID, module, p.Description, p.Group, p.Order,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("auth repo sync permissions: %w", err)
+	}
+
+	deprecated, err := collectStrings(tx.Query(ctx, `
+		UPDATE permissions SET deprecated_at = now()
+		WHERE module = $1 AND deprecated_at IS NULL AND NOT (id = ANY($2))
+		RETURNING id`,
+		module, ids))
+	if err != nil {
+		return nil, fmt.Errorf("auth repo sync permissions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("auth repo sync permissions: %w", err)
+	}
+	return deprecated, nil
+}
+
+// GetPermissions lists the permissions modules currently declare.
+func (r *pgxRepo) GetPermissions(ctx context.Context) ([]domain.Permission, error) {
+	query := `
+		SELECT id, module, COALESCE(description, ''), group_name, sort_order, created_at, deprecated_at
+		FROM permissions
+		WHERE deprecated_at IS NULL
+		ORDER BY module, sort_order, id
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.ID, &p.Module, &p.Description, &p.Group, &p.Order, &p.CreatedAt, &p.DeprecatedAt); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
+
+// GetPermissionIDs lists every registered permission ID, deprecated ones included.
+func (r *pgxRepo) GetPermissionIDs(ctx context.Context) ([]string, error) {
+	ids, err := collectStrings(r.pool.Query(ctx, `SELECT id FROM permissions`))
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get permission ids: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *pgxRepo) GetDeprecatedPermissions(ctx context.Context) ([]domain.DeprecatedPermission, error) {
+	query := `
+		SELECT p.id, p.module, COALESCE(p.description, ''), p.group_name, p.sort_order, p.created_at, p.deprecated_at,
+			r.id, r.name, r.require_mfa
+		FROM permissions p
+		LEFT JOIN role_permissions rp ON rp.permission_id = p.id
+		LEFT JOIN roles r ON r.id = rp.role_id
+		WHERE p.deprecated_at IS NOT NULL
+		ORDER BY p.module, p.id, r.name
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get deprecated permissions: %w", err)
+	}
+	defer rows.Close()
+
+	perms := []domain.DeprecatedPermission{}
+	for rows.Next() {
+		var p domain.Permission
+		var roleID *int
+		var roleName *string
+		var roleMFA *bool
+		if err := rows.Scan(&p.ID, &p.Module, &p.Description, &p.Group, &p.Order, &p.CreatedAt, &p.DeprecatedAt,
+			&roleID, &roleName, &roleMFA); err != nil {
+			return nil, err
+		}
+		if n := len(perms); n == 0 || perms[n-1].ID != p.ID {
+			perms = append(perms, domain.DeprecatedPermission{Permission: p, Roles: []domain.Role{}})
+		}
+		if roleID != nil {
+			last := &perms[len(perms)-1]
+			last.Roles = append(last.Roles, domain.Role{ID: *roleID, Name: *roleName, RequireMFA: *roleMFA})
+		}
+	}
+	return perms, nil
+}
+
+// PurgeDeprecatedPermissions deletes every deprecated permission together with its role grants.
+func (r *pgxRepo) PurgeDeprecatedPermissions(ctx context.Context) ([]string, []domain.RoleGrant, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Locking the rows keeps a module from declaring them again halfway through the purge.
+	ids, err := collectStrings(tx.Query(ctx, `SELECT id FROM permissions WHERE deprecated_at IS NOT NULL FOR UPDATE`))
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+	if len(ids) == 0 {
+		return ids, []domain.RoleGrant{}, nil
+	}
+
+	rows, err := tx.Query(ctx, `DELETE FROM role_permissions WHERE permission_id = ANY($1) RETURNING role_id, permission_id`, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+	grants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.RoleGrant, error) {
+		var g domain.RoleGrant
+		err := row.Scan(&g.RoleID, &g.PermissionID)
+		return g, err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM permissions WHERE id = ANY($1)`, ids); err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("auth repo purge permissions: %w", err)
+	}
+	return ids, grants, nil
+}
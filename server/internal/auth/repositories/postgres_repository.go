@@ -22,10 +22,9 @@ func NewPgxRepository(pool *pgxpool.Pool) domain.Repository {
 }
 
 func (r *pgxRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, email, password_hash, full_name FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 
-	var user domain.User
-	err := r.pool.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.FullName)
+	user, err := scanUser(r.pool.QueryRow(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, httputil.ErrNotFound
@@ -33,58 +32,68 @@ func (r *pgxRepo) GetUserByEmail(ctx context.Context, email string) (*domain.Use
 		return nil, fmt.Errorf("auth repo get user by email: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *pgxRepo) CreateUser(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (id, email, password_hash, full_name) VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, query, user.ID, user.Email, user.PasswordHash, user.FullName)
+	query := `INSERT INTO users (id, email, password_hash, full_name, activated_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.pool.Exec(ctx, query, user.ID, user.Email, user.PasswordHash, user.FullName, user.ActivatedAt)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return httputil.ErrConflict
+		}
 		return fmt.Errorf("auth repo create user: %w", err)
 	}
 	return nil
 }
 
-func (r *pgxRepo) UpsertPermissions(ctx context.Context, permissions []domain.Permission) error {
-	if len(permissions) == 0 {
-		return nil
-	}
-	batch := &pgx.Batch{}
-	for _, p := range permissions {
-		batch.Queue(`
-			INSERT INTO permissions (id, module, description) 
-			VALUES ($1, $2, $3) 
-			ON CONFLICT (id) DO UPDATE SET module = EXCLUDED.module, description = EXCLUDED.description`,
-			p.ID, p.Module, p.Description,
-		)
+// ActivateUser sets activated_at once. It reports whether the account was activated by this call.
+func (r *pgxRepo) ActivateUser(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `UPDATE users SET activated_at = now(), updated_at = now() WHERE id = $1 AND activated_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return false, fmt.Errorf("auth repo activate user: %w", err)
 	}
-	br := r.pool.SendBatch(ctx, batch)
-	defer func(br pgx.BatchResults) {
-		err := br.Close()
-		if err != nil {
-			log.Printf("auth repo upsert permissions: %v", err)
-			return
-		}
-	}(br)
-	_, err := br.Exec()
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgxRepo) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = now() WHERE id = $2`
+	tag, err := r.pool.Exec(ctx, query, passwordHash, userID)
 	if err != nil {
-		return fmt.Errorf("auth repo upsert permissions: %w", err)
+		return fmt.Errorf("auth repo update password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
+
+// RehashPassword swaps the stored hash for an upgraded hash of the same password. It does nothing when
+// the password was changed since currentHash was read.
+func (r *pgxRepo) RehashPassword(ctx context.Context, userID uuid.UUID, currentHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $3 WHERE id = $1 AND password_hash = $2`
+	if _, err := r.pool.Exec(ctx, query, userID, currentHash, newHash); err != nil {
+		return fmt.Errorf("auth repo rehash password: %w", err)
 	}
 	return nil
 }
 
 func (r *pgxRepo) CreateRole(ctx context.Context, name string) (*domain.Role, error) {
-	query := `INSERT INTO roles (name) VALUES ($1) RETURNING id, name`
+	query := `INSERT INTO roles (name) VALUES ($1) RETURNING id, name, require_mfa`
 	var role domain.Role
-	err := r.pool.QueryRow(ctx, query, name).Scan(&role.ID, &role.Name)
+	err := r.pool.QueryRow(ctx, query, name).Scan(&role.ID, &role.Name, &role.RequireMFA)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, httputil.ErrConflict
+		}
 		return nil, fmt.Errorf("auth repo create role: %w", err)
 	}
 	return &role, nil
 }
 
 func (r *pgxRepo) GetRoles(ctx context.Context) ([]domain.Role, error) {
-	query := `SELECT id, name FROM roles ORDER BY id`
+	query := `SELECT id, name, require_mfa FROM roles ORDER BY id`
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("auth repo get roles: %w", err)
@@ -94,7 +103,7 @@ func (r *pgxRepo) GetRoles(ctx context.Context) ([]domain.Role, error) {
 	var roles []domain.Role
 	for rows.Next() {
 		var role domain.Role
-		if err := rows.Scan(&role.ID, &role.Name); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.RequireMFA); err != nil {
 			return nil, err
 		}
 		roles = append(roles, role)
@@ -102,22 +111,31 @@ func (r *pgxRepo) GetRoles(ctx context.Context) ([]domain.Role, error) {
 	return roles, nil
 }
 
-func (r *pgxRepo) AssignRoleToUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+// AssignRoleToUser grants the role and reports whether the user did not hold it yet.
+func (r *pgxRepo) AssignRoleToUser(ctx context.Context, userID uuid.UUID, roleID int) (bool, error) {
 	query := `INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-	_, err := r.pool.Exec(ctx, query, userID, roleID)
+	tag, err := r.pool.Exec(ctx, query, userID, roleID)
 	if err != nil {
-		return fmt.Errorf("auth repo assign role: %w", err)
+		if isForeignKeyViolation(err) {
+			return false, httputil.ErrNotFound
+		}
+		return false, fmt.Errorf("auth repo assign role: %w", err)
 	}
-	return nil
+	return tag.RowsAffected() == 1, nil
 }
 
+// GetUserPermissions resolves the effective grants of the user: those of their roles and of every role
+// those inherit from. Wildcard grants are returned as stored.
 func (r *pgxRepo) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
 	query := `
-		SELECT DISTINCT p.id
-		FROM permissions p
-		JOIN role_permissions rp ON p.id = rp.permission_id
-		JOIN user_roles ur ON rp.role_id = ur.role_id
-		WHERE ur.user_id = $1
+		WITH RECURSIVE effective_roles(role_id) AS (
+			SELECT role_id FROM user_roles WHERE user_id = $1
+			UNION
+			SELECT rp.parent_id FROM role_parents rp JOIN effective_roles er ON rp.role_id = er.role_id
+		)
+		SELECT DISTINCT rp.permission_id
+		FROM role_permissions rp
+		JOIN effective_roles er ON rp.role_id = er.role_id
 	`
 	rows, err := r.pool.Query(ctx, query, userID)
 	if err != nil {
@@ -136,13 +154,35 @@ func (r *pgxRepo) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]s
 	return perms, nil
 }
 
-func (r *pgxRepo) AddPermissionToRole(ctx context.Context, roleID int, permissionID string) error {
+// GetUserRoleIDs lists the roles of the user together with every role they inherit from.
+func (r *pgxRepo) GetUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]int, error) {
+	query := `
+		WITH RECURSIVE effective_roles(role_id) AS (
+			SELECT role_id FROM user_roles WHERE user_id = $1
+			UNION
+			SELECT rp.parent_id FROM role_parents rp JOIN effective_roles er ON rp.role_id = er.role_id
+		)
+		SELECT role_id FROM effective_roles ORDER BY role_id
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get user roles: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get user roles: %w", err)
+	}
+	return ids, nil
+}
+
+// AddPermissionToRole grants the permission and reports whether the role did not have it yet.
+func (r *pgxRepo) AddPermissionToRole(ctx context.Context, roleID int, permissionID string) (bool, error) {
 	query := `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-	_, err := r.pool.Exec(ctx, query, roleID, permissionID)
+	tag, err := r.pool.Exec(ctx, query, roleID, permissionID)
 	if err != nil {
-		return fmt.Errorf("auth repo add permission to role: %w", err)
+		return false, fmt.Errorf("auth repo add permission to role: %w", err)
 	}
-	return nil
+	return tag.RowsAffected() == 1, nil
 }
 
 func (r *pgxRepo) UpsertMenuDefinitions(ctx context.Context, defs []domain.MenuDefinition) error {
@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (r *pgxRepo) GetRole(ctx context.Context, id int) (*domain.Role, error) {
+	query := `SELECT id, name, require_mfa FROM roles WHERE id = $1`
+	var role domain.Role
+	err := r.pool.QueryRow(ctx, query, id).Scan(&role.ID, &role.Name, &role.RequireMFA)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get role: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *pgxRepo) GetRoleByName(ctx context.Context, name string) (*domain.Role, error) {
+	query := `SELECT id, name, require_mfa FROM roles WHERE name = $1`
+	var role domain.Role
+	err := r.pool.QueryRow(ctx, query, name).Scan(&role.ID, &role.Name, &role.RequireMFA)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get role by name: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *pgxRepo) GetRolePermissions(ctx context.Context, roleID int) ([]string, error) {
+	query := `SELECT permission_id FROM role_permissions WHERE role_id = $1 ORDER BY permission_id`
+	rows, err := r.pool.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	perms := []string{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
+
+func (r *pgxRepo) GetRoleMembers(ctx context.Context, roleID int) ([]domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users JOIN user_roles ur ON ur.user_id = users.id
+		WHERE ur.role_id = $1
+		ORDER BY email
+	`
+	rows, err := r.pool.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get role members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []domain.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *u)
+	}
+	return members, nil
+}
+
+// GetUserRoles returns the roles assigned to the user directly, without the ones they inherit from.
+func (r *pgxRepo) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	query := `
+		SELECT ro.id, ro.name, ro.require_mfa
+		FROM user_roles ur JOIN roles ro ON ro.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY ro.name
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []domain.Role{}
+	for rows.Next() {
+		var role domain.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.RequireMFA); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *pgxRepo) RenameRole(ctx context.Context, id int, name string) (*domain.Role, error) {
+	query := `UPDATE roles SET name = $2 WHERE id = $1 RETURNING id, name, require_mfa`
+	var role domain.Role
+	err := r.pool.QueryRow(ctx, query, id, name).Scan(&role.ID, &role.Name, &role.RequireMFA)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, httputil.ErrConflict
+		}
+		return nil, fmt.Errorf("auth repo rename role: %w", err)
+	}
+	return &role, nil
+}
+
+// DeleteRole removes a role that nobody holds and no other role inherits from. It returns
+// domain.ErrRoleInUse or domain.ErrRoleHasChildren otherwise; the row lock keeps concurrent
+// assignments from slipping through.
+func (r *pgxRepo) DeleteRole(ctx context.Context, id int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo delete role: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT true FROM roles WHERE id = $1 FOR UPDATE`, id).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httputil.ErrNotFound
+		}
+		return fmt.Errorf("auth repo delete role: %w", err)
+	}
+
+	var hasChildren bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM role_parents WHERE parent_id = $1)`, id).Scan(&hasChildren); err != nil {
+		return fmt.Errorf("auth repo delete role: %w", err)
+	}
+	if hasChildren {
+		return domain.ErrRoleHasChildren
+	}
+
+	query := `DELETE FROM roles WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM user_roles WHERE role_id = $1)`
+	tag, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("auth repo delete role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleInUse
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepo) RemovePermissionFromRole(ctx context.Context, roleID int, permissionID string) error {
+	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
+	tag, err := r.pool.Exec(ctx, query, roleID, permissionID)
+	if err != nil {
+		return fmt.Errorf("auth repo remove permission from role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepo) UnassignRoleFromUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
+	tag, err := r.pool.Exec(ctx, query, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("auth repo unassign role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
+
+// SetRolePermissions makes permissionIDs the exact permission set of the role in one transaction
+// and returns what was added and removed.
+func (r *pgxRepo) SetRolePermissions(ctx context.Context, roleID int, permissionIDs []string) ([]string, []string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo set role permissions: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialises concurrent edits of the same role.
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT true FROM roles WHERE id = $1 FOR UPDATE`, roleID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, httputil.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("auth repo set role permissions: %w", err)
+	}
+
+	removed, err := collectStrings(tx.Query(ctx,
+		`DELETE FROM role_permissions WHERE role_id = $1 AND NOT (permission_id = ANY($2)) RETURNING permission_id`,
+		roleID, permissionIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo set role permissions: %w", err)
+	}
+
+	added, err := collectStrings(tx.Query(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING
+		RETURNING permission_id`,
+		roleID, permissionIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth repo set role permissions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("auth repo set role permissions: %w", err)
+	}
+	return added, removed, nil
+}
+
+func (r *pgxRepo) GetRoleParents(ctx context.Context, roleID int) ([]domain.Role, error) {
+	query := `
+		SELECT ro.id, ro.name, ro.require_mfa
+		FROM role_parents rp JOIN roles ro ON ro.id = rp.parent_id
+		WHERE rp.role_id = $1
+		ORDER BY ro.name
+	`
+	rows, err := r.pool.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get role parents: %w", err)
+	}
+	defer rows.Close()
+
+	parents := []domain.Role{}
+	for rows.Next() {
+		var role domain.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.RequireMFA); err != nil {
+			return nil, err
+		}
+		parents = append(parents, role)
+	}
+	return parents, nil
+}
+
+// GetRoleInheritedPermissions lists the permissions a role only has through its ancestors.
+func (r *pgxRepo) GetRoleInheritedPermissions(ctx context.Context, roleID int) ([]string, error) {
+	query := `
+		WITH RECURSIVE ancestors(role_id) AS (
+			SELECT parent_id FROM role_parents WHERE role_id = $1
+			UNION
+			SELECT rp.parent_id FROM role_parents rp JOIN ancestors a ON rp.role_id = a.role_id
+		)
+		SELECT DISTINCT rp.permission_id
+		FROM role_permissions rp JOIN ancestors a ON rp.role_id = a.role_id
+		WHERE rp.permission_id NOT IN (SELECT permission_id FROM role_permissions WHERE role_id = $1)
+		ORDER BY rp.permission_id
+	`
+	perms, err := collectStrings(r.pool.Query(ctx, query, roleID))
+	if err != nil {
+		return nil, fmt.Errorf("auth repo get inherited permissions: %w", err)
+	}
+	return perms, nil
+}
+
+// SetRoleParents replaces the parents of a role. It returns domain.ErrRoleCycle when the new parents
+// would make the role inherit from itself.
+func (r *pgxRepo) SetRoleParents(ctx context.Context, roleID int, parentIDs []int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Two concurrent writes could each be acyclic on their own and form a cycle together,
+	// so hierarchy changes are serialised.
+	if _, err := tx.Exec(ctx, `LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT true FROM roles WHERE id = $1`, roleID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httputil.ErrNotFound
+		}
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT role_id, parent_id FROM role_parents`)
+	if err != nil {
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+	edges := make(map[int][]int)
+	var child, parent int
+	_, err = pgx.ForEachRow(rows, []any{&child, &parent}, func() error {
+		edges[child] = append(edges[child], parent)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+	if _, cycle := domain.RoleCyclePath(edges, roleID, parentIDs); cycle {
+		return domain.ErrRoleCycle
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM role_parents WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO role_parents (role_id, parent_id)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT DO NOTHING`,
+		roleID, parentIDs)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrUnknownRole
+		}
+		return fmt.Errorf("auth repo set role parents: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func collectStrings(rows pgx.Rows, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// CreateSession stores a session with its first refresh token. Impersonation sessions have none, token is nil.
+func (r *pgxRepo) CreateSession(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo create session: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, amr, expires_at, impersonator_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, last_used_at
+	`
+	err = tx.QueryRow(ctx, query, session.ID, session.UserID, session.UserAgent, session.IPAddress, session.AMR, session.ExpiresAt, session.ImpersonatorID).
+		Scan(&session.CreatedAt, &session.LastUsedAt)
+	if err != nil {
+		return fmt.Errorf("auth repo create session: %w", err)
+	}
+	if token == nil {
+		return tx.Commit(ctx)
+	}
+
+	query = `INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, query, token.ID, token.SessionID, token.TokenHash, token.ExpiresAt); err != nil {
+		return fmt.Errorf("auth repo create refresh token: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepo) GetSession(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, amr, created_at, last_used_at, expires_at, revoked_at, impersonator_id
+		FROM sessions WHERE id = $1
+	`
+	var s domain.Session
+	err := r.pool.QueryRow(ctx, query, id).
+		Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.AMR, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt, &s.ImpersonatorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get session: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *pgxRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `SELECT id, session_id, token_hash, created_at, expires_at, used_at FROM refresh_tokens WHERE token_hash = $1`
+	var t domain.RefreshToken
+	err := r.pool.QueryRow(ctx, query, tokenHash).
+		Scan(&t.ID, &t.SessionID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get refresh token: %w", err)
+	}
+	return &t, nil
+}
+
+// RotateRefreshToken marks the presented token as used and stores its successor in one transaction.
+// It returns httputil.ErrConflict when the token was already used, which callers must treat as reuse.
+func (r *pgxRepo) RotateRefreshToken(ctx context.Context, usedID uuid.UUID, next *domain.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("auth repo rotate refresh token: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = now() WHERE id = $1 AND used_at IS NULL`, usedID)
+	if err != nil {
+		return fmt.Errorf("auth repo rotate refresh token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrConflict
+	}
+
+	query := `INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, query, next.ID, next.SessionID, next.TokenHash, next.ExpiresAt); err != nil {
+		return fmt.Errorf("auth repo rotate refresh token: %w", err)
+	}
+
+	query = `UPDATE sessions SET last_used_at = now(), expires_at = $2 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, next.SessionID, next.ExpiresAt); err != nil {
+		return fmt.Errorf("auth repo rotate refresh token: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepo) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, amr, created_at, last_used_at, expires_at, revoked_at, impersonator_id
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []domain.Session
+	for rows.Next() {
+		var s domain.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.AMR, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt, &s.ImpersonatorID); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+func (r *pgxRepo) IsSessionActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL AND expires_at > now())`
+	var active bool
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&active); err != nil {
+		return false, fmt.Errorf("auth repo is session active: %w", err)
+	}
+	return active, nil
+}
+
+func (r *pgxRepo) RevokeSession(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("auth repo revoke session: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepo) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("auth repo revoke user session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepo) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("auth repo revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeOtherUserSessions signs the user out everywhere except keepID, the session making the request.
+func (r *pgxRepo) RevokeOtherUserSessions(ctx context.Context, userID, keepID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, userID, keepID); err != nil {
+		return fmt.Errorf("auth repo revoke other user sessions: %w", err)
+	}
+	return nil
+}
+
+// AddSessionAMR records an additional authentication method on a session, e.g. after the user
+// enrolled a second factor from within it.
+func (r *pgxRepo) AddSessionAMR(ctx context.Context, sessionID uuid.UUID, method string) error {
+	query := `UPDATE sessions SET amr = array_append(amr, $2) WHERE id = $1 AND NOT ($2 = ANY(amr))`
+	if _, err := r.pool.Exec(ctx, query, sessionID, method); err != nil {
+		return fmt.Errorf("auth repo add session amr: %w", err)
+	}
+	return nil
+}
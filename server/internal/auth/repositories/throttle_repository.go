@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+// CountLoginAttempt counts an attempt as a failure before its credentials are checked. The counter
+// row stays locked while admit looks at its current state, so concurrent attempts are decided one at a
+// time; the attempt is only counted when admit returns nil, and admit's error is returned otherwise.
+// Counters whose last failure happened before resetBefore, or whose lockout has expired, start over.
+func (r *pgxRepo) CountLoginAttempt(ctx context.Context, scope, key string, resetBefore time.Time, admit func(*domain.LoginThrottle) error) (*domain.LoginThrottle, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo count login attempt: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	insert := `
+		INSERT INTO login_throttles (scope, key, failures, last_failure_at)
+		VALUES ($1, $2, 0, now())
+		ON CONFLICT (scope, key) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, insert, scope, key); err != nil {
+		return nil, fmt.Errorf("auth repo count login attempt: %w", err)
+	}
+
+	query := `
+		SELECT scope, key,
+			CASE WHEN last_failure_at < $3 OR locked_until <= now() THEN 0 ELSE failures END,
+			last_failure_at,
+			CASE WHEN locked_until <= now() THEN NULL ELSE locked_until END
+		FROM login_throttles
+		WHERE scope = $1 AND key = $2
+		FOR UPDATE
+	`
+	var t domain.LoginThrottle
+	err = tx.QueryRow(ctx, query, scope, key, resetBefore).
+		Scan(&t.Scope, &t.Key, &t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo count login attempt: %w", err)
+	}
+	if err := admit(&t); err != nil {
+		return nil, err
+	}
+
+	update := `
+		UPDATE login_throttles SET failures = $3 + 1, last_failure_at = now(), locked_until = $4
+		WHERE scope = $1 AND key = $2
+		RETURNING scope, key, failures, last_failure_at, locked_until
+	`
+	var counted domain.LoginThrottle
+	err = tx.QueryRow(ctx, update, scope, key, t.Failures, t.LockedUntil).
+		Scan(&counted.Scope, &counted.Key, &counted.Failures, &counted.LastFailureAt, &counted.LockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("auth repo count login attempt: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("auth repo count login attempt: %w", err)
+	}
+	return &counted, nil
+}
+
+// ReleaseLoginAttempt takes back an attempt counted by CountLoginAttempt that did not fail. The time
+// of the previous failure is restored unless another attempt has been counted since.
+func (r *pgxRepo) ReleaseLoginAttempt(ctx context.Context, scope, key string, countedAt, previousAt time.Time) error {
+	query := `
+		UPDATE login_throttles SET
+			failures = greatest(failures - 1, 0),
+			last_failure_at = CASE WHEN last_failure_at = $3 THEN $4 ELSE last_failure_at END
+		WHERE scope = $1 AND key = $2
+	`
+	if _, err := r.pool.Exec(ctx, query, scope, key, countedAt, previousAt); err != nil {
+		return fmt.Errorf("auth repo release login attempt: %w", err)
+	}
+	return nil
+}
+
+// LockLogin sets the lockout deadline. It returns false when the key was already locked,
+// so concurrent failures only report the lockout once.
+func (r *pgxRepo) LockLogin(ctx context.Context, scope, key string, until time.Time) (bool, error) {
+	query := `UPDATE login_throttles SET locked_until = $3 WHERE scope = $1 AND key = $2 AND locked_until IS NULL`
+	tag, err := r.pool.Exec(ctx, query, scope, key, until)
+	if err != nil {
+		return false, fmt.Errorf("auth repo lock login: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepo) ClearLoginThrottle(ctx context.Context, scope, key string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM login_throttles WHERE scope = $1 AND key = $2`, scope, key); err != nil {
+		return fmt.Errorf("auth repo clear login throttle: %w", err)
+	}
+	return nil
+}
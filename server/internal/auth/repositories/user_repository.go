@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const userColumns = `id, email, password_hash, full_name, created_at, updated_at, activated_at, archived_at`
+
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var u domain.User
+	err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.FullName, &u.CreatedAt, &u.UpdatedAt, &u.ActivatedAt, &u.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *pgxRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get user by id: %w", err)
+	}
+	return user, nil
+}
+
+func (r *pgxRepo) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, int, error) {
+	var conditions []string
+	var args []any
+
+	switch filter.Status {
+	case domain.UserStatusArchived:
+		conditions = append(conditions, "archived_at IS NOT NULL")
+	case domain.UserStatusAll:
+	default:
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("(email ILIKE $%d OR full_name ILIKE $%d)", len(args), len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT %s, count(*) OVER () AS total
+		FROM users
+		%s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, userColumns, where, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("auth repo list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	total := 0
+	for rows.Next() {
+		var u domain.User
+		err := rows.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.FullName, &u.CreatedAt, &u.UpdatedAt, &u.ActivatedAt, &u.ArchivedAt, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("auth repo list users: %w", err)
+	}
+
+	if len(users) == 0 && filter.Offset > 0 {
+		// The window count is only available on returned rows; fall back to a plain count past the last page.
+		countQuery := `SELECT count(*) FROM users ` + where
+		if err := r.pool.QueryRow(ctx, countQuery, args[:len(args)-2]...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("auth repo count users: %w", err)
+		}
+	}
+	return users, total, nil
+}
+
+func (r *pgxRepo) UpdateUserProfile(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET email = $1, full_name = $2, updated_at = now() WHERE id = $3 RETURNING updated_at`
+	err := r.pool.QueryRow(ctx, query, user.Email, user.FullName, user.ID).Scan(&user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httputil.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return httputil.ErrConflict
+		}
+		return fmt.Errorf("auth repo update user profile: %w", err)
+	}
+	return nil
+}
+
+// ArchiveUser soft-deletes the user. It reports whether the user was archived by this call.
+func (r *pgxRepo) ArchiveUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE users SET archived_at = now(), updated_at = now() WHERE id = $1 AND archived_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("auth repo archive user: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RestoreUser clears archived_at. It reports whether the user was restored by this call.
+func (r *pgxRepo) RestoreUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE users SET archived_at = NULL, updated_at = now() WHERE id = $1 AND archived_at IS NOT NULL`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("auth repo restore user: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (r *pgxRepo) CreateUserToken(ctx context.Context, token *domain.UserToken) error {
+	query := `INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.pool.Exec(ctx, query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("auth repo create user token: %w", err)
+	}
+	return nil
+}
+
+// GetUserToken returns a valid token without consuming it, so the request can be validated first.
+// Unknown, expired and already used tokens yield httputil.ErrNotFound.
+func (r *pgxRepo) GetUserToken(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, created_at, expires_at, used_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now()
+	`
+	var t domain.UserToken
+	err := r.pool.QueryRow(ctx, query, tokenHash, purpose).
+		Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo get user token: %w", err)
+	}
+	return &t, nil
+}
+
+// ConsumeUserToken marks a valid token as used and returns it. Unknown, expired and already used
+// tokens all yield httputil.ErrNotFound, and concurrent calls can never consume the same token twice.
+func (r *pgxRepo) ConsumeUserToken(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error) {
+	query := `
+		UPDATE user_tokens SET used_at = now()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, purpose, token_hash, created_at, expires_at, used_at
+	`
+	var t domain.UserToken
+	err := r.pool.QueryRow(ctx, query, tokenHash, purpose).
+		Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
+		return nil, fmt.Errorf("auth repo consume user token: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *pgxRepo) InvalidateUserTokens(ctx context.Context, userID uuid.UUID, purpose string) error {
+	query := `UPDATE user_tokens SET used_at = now() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, userID, purpose); err != nil {
+		return fmt.Errorf("auth repo invalidate user tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const apiKeyNameMaxLength = 100
+
+var apiKeyPrefixEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CreateAPIKey issues a key limited to the given scopes, all of which must currently be granted to the owner.
+// The returned raw key is not stored and cannot be shown again. Users who still have to enroll a second
+// factor required by one of their roles are refused, as a key would let them skip it.
+func (a authService) CreateAPIKey(ctx context.Context, userID uuid.UUID, input domain.NewAPIKey) (*domain.APIKey, string, error) {
+	pending, err := a.mfaEnrollmentPending(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if pending {
+		return nil, "", domain.ErrMFAEnrollmentPending
+	}
+
+	name := strings.TrimSpace(input.Name)
+	invalid := &httputil.ValidationError{}
+	if name == "" {
+		invalid.Add("name", "is required")
+	} else if utf8.RuneCountInString(name) > apiKeyNameMaxLength {
+		invalid.Add("name", fmt.Sprintf("must be at most %d characters long", apiKeyNameMaxLength))
+	}
+
+	scopes := slices.Compact(slices.Sorted(slices.Values(input.Scopes)))
+	if len(scopes) == 0 {
+		invalid.Add("scopes", "must list at least one permission")
+	} else {
+		perms, err := a.GetUserPermissions(ctx, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		granted := authz.NewGrants(perms)
+		for _, s := range scopes {
+			if !granted.Has(s) {
+				invalid.Add("scopes", fmt.Sprintf("%q is not one of your permissions", s))
+			}
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.cfg.APIKeyDefaultTTL)
+	if input.ExpiresAt != nil {
+		expiresAt = *input.ExpiresAt
+	}
+	if !expiresAt.After(now) {
+		invalid.Add("expires_at", "must be in the future")
+	} else if expiresAt.After(now.Add(a.cfg.APIKeyMaxTTL)) {
+		invalid.Add("expires_at", fmt.Sprintf("must be at most %s from now", a.cfg.APIKeyMaxTTL))
+	}
+
+	if err := invalid.OrNil(); err != nil {
+		return nil, "", err
+	}
+
+	raw, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := a.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	a.publish(ctx, events.AuthAPIKeyCreated, events.AuthAPIKeyCreatedData{
+		KeyID:     key.ID,
+		UserID:    userID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		ExpiresAt: key.ExpiresAt,
+	})
+	return key, raw, nil
+}
+
+func (a authService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	return a.repo.ListAPIKeys(ctx, userID)
+}
+
+func (a authService) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	if err := a.repo.RevokeAPIKey(ctx, userID, id); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthAPIKeyRevoked, events.AuthAPIKeyRevokedData{KeyID: id, UserID: userID})
+	return nil
+}
+
+// ValidateAPIKey resolves a key to claims equivalent to those of an access token. The scopes are
+// carried along so permission checks can intersect them with the owner's current permissions.
+// Keys are held back like MFA-pending sessions while their owner has a role requiring a second factor
+// they have not enrolled, which covers keys created before the role was assigned.
+func (a authService) ValidateAPIKey(ctx context.Context, key string) (*domain.UserClaims, error) {
+	k, err := a.repo.GetActiveAPIKey(ctx, hashToken(key))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, httputil.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	pending, err := a.mfaEnrollmentPending(ctx, k.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.TouchAPIKey(ctx, k.ID); err != nil {
+		// Usage tracking must not fail the request it describes.
+		slog.Error("failed to record api key usage", "key_id", k.ID, "error", err)
+	}
+
+	return &domain.UserClaims{
+		UserID:     k.UserID.String(),
+		APIKeyID:   k.ID.String(),
+		Scopes:     k.Scopes,
+		MFAPending: pending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   k.UserID.String(),
+			ExpiresAt: jwt.NewNumericDate(k.ExpiresAt),
+		},
+	}, nil
+}
+
+// generateAPIKey returns a key of the form tfs_<prefix>_<secret> and its visible prefix.
+func generateAPIKey() (string, string, error) {
+	p := make([]byte, 5)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", err
+	}
+	s := make([]byte, 32)
+	if _, err := rand.Read(s); err != nil {
+		return "", "", err
+	}
+
+	prefix := strings.ToLower(apiKeyPrefixEncoding.EncodeToString(p))
+	return domain.APIKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(s), prefix, nil
+}
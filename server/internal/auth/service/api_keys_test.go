@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// apiKeyRepo serves one stored key on top of the role lookups of sessionRepo.
+type apiKeyRepo struct {
+	sessionRepo
+	key    domain.APIKey
+	factor *domain.UserMFA
+}
+
+func (r *apiKeyRepo) GetActiveAPIKey(_ context.Context, keyHash string) (*domain.APIKey, error) {
+	if keyHash != r.key.KeyHash {
+		return nil, httputil.ErrNotFound
+	}
+	return &r.key, nil
+}
+
+func (r *apiKeyRepo) TouchAPIKey(context.Context, uuid.UUID) error {
+	return nil
+}
+
+func (r *apiKeyRepo) GetUserMFA(context.Context, uuid.UUID) (*domain.UserMFA, error) {
+	if r.factor == nil {
+		return nil, httputil.ErrNotFound
+	}
+	return r.factor, nil
+}
+
+func TestAPIKeysHeldBackUntilRequiredMFAIsEnrolled(t *testing.T) {
+	userID := uuid.New()
+	repo := &apiKeyRepo{
+		sessionRepo: sessionRepo{userRoles: map[uuid.UUID][]int{userID: {2}}, mfaRoles: map[int]bool{2: true}},
+		key:         domain.APIKey{ID: uuid.New(), UserID: userID, KeyHash: hashToken("tfs_key"), ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	svc := authService{repo: repo}
+	ctx := context.Background()
+
+	if _, _, err := svc.CreateAPIKey(ctx, userID, domain.NewAPIKey{Name: "CI"}); !errors.Is(err, domain.ErrMFAEnrollmentPending) {
+		t.Fatalf("expected a user without the required factor to be refused a key, got %v", err)
+	}
+	claims, err := svc.ValidateAPIKey(ctx, "tfs_key")
+	if err != nil {
+		t.Fatalf("validate key: %v", err)
+	}
+	if !claims.MFAPending {
+		t.Fatal("expected a key created before the role was assigned to be held back")
+	}
+
+	confirmed := time.Now()
+	repo.factor = &domain.UserMFA{UserID: userID, ConfirmedAt: &confirmed}
+	claims, err = svc.ValidateAPIKey(ctx, "tfs_key")
+	if err != nil {
+		t.Fatalf("validate key: %v", err)
+	}
+	if claims.MFAPending {
+		t.Fatal("expected the key to work once the factor is confirmed")
+	}
+}
@@ -2,80 +2,182 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	netmail "net/mail"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/mail"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/password"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// Config holds the settings the auth service needs to issue tokens.
+type Config struct {
+	Keys            *KeyRing
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	Mailer           mail.Sender
+	AppURL           string // Base URL of the backoffice, used to build links in emails
+	PasswordResetTTL time.Duration
+	VerificationTTL  time.Duration
+	InvitationTTL    time.Duration
+
+	AllowRegistration bool // Open self-registration; when false staff join by invitation only
+
+	MFAIssuer       string // Issuer label shown by authenticator apps
+	MFAChallengeTTL time.Duration
+
+	AccountThrottle ThrottlePolicy // Failed logins per email
+	IPThrottle      ThrottlePolicy // Failed logins per client IP
+
+	PasswordPolicy password.Policy
+	Hasher         *password.Hasher
+
+	APIKeyDefaultTTL time.Duration
+	APIKeyMaxTTL     time.Duration
+
+	AccessCacheTTL time.Duration // Lifetime of cached permissions and menus; zero disables the cache
+
+	ImpersonationTTL time.Duration // Lifetime of impersonation sessions, which cannot be refreshed
+
+	Audit audit.Recorder // Records logins, which publish no event; may be nil
+
+	OIDC           *oidc.Provider    // Single sign-on provider; nil disables it
+	OIDCStateTTL   time.Duration     // Time the user has to sign in at the provider
+	OIDCGroupRoles map[string]string // Provider group to local role name
+}
+
+// publisher is the part of *nats.Conn the service publishes its events through.
+type publisher interface {
+	PublishMsg(msg *nats.Msg) error
+}
+
 type authService struct {
-	repo      domain.Repository
-	nc        *nats.Conn
-	jwtSecret string
+	repo  domain.Repository
+	nc    publisher
+	cfg   Config
+	cache *accessCache
 }
 
-func NewAuthService(repository domain.Repository, nc *nats.Conn, jwtSecret string) domain.Service {
+func NewAuthService(repository domain.Repository, nc *nats.Conn, cfg Config) domain.Service {
 	return &authService{
-		repo:      repository,
-		nc:        nc,
-		jwtSecret: jwtSecret,
+		repo:  repository,
+		nc:    nc,
+		cfg:   cfg,
+		cache: newAccessCache(cfg.AccessCacheTTL),
 	}
 }
 
-func (a authService) Login(ctx context.Context, email, password string) (string, error) {
+func (a authService) Login(ctx context.Context, email, password string, meta domain.SessionMeta) (*domain.LoginResult, error) {
+	attempt, err := a.admitLoginAttempt(ctx, a.loginTargets(email, meta))
+	if err != nil {
+		return nil, err
+	}
+	defer attempt.release(ctx)
+
 	u, err := a.repo.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, httputil.ErrNotFound) {
-			return "", httputil.ErrUnauthorized // Don't reveal user existence
+			// Unknown emails are counted too, so throttling does not reveal which accounts exist.
+			attempt.failed(ctx, nil)
+			return nil, httputil.ErrUnauthorized // Don't reveal user existence
 		}
-		return "", err
+		return nil, err
+	}
+
+	match, needsRehash := a.verifyPassword(u, password)
+	if !match {
+		attempt.failed(ctx, &u.ID)
+		return nil, httputil.ErrUnauthorized
+	}
+	if needsRehash {
+		a.upgradePasswordHash(ctx, u, password)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", httputil.ErrUnauthorized
+	// Checked after the password so the distinct errors cannot be used to probe for accounts.
+	if u.ArchivedAt != nil {
+		return nil, domain.ErrAccountArchived
+	}
+	if u.ActivatedAt == nil {
+		return nil, domain.ErrAccountNotActivated
 	}
 
-	claims := domain.UserClaims{
-		UserID: u.ID.String(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   u.ID.String(),
-		},
+	factor, err := a.confirmedMFA(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	// With a second factor the account counter is only cleared once the code is verified,
+	// otherwise repeating the password step would allow unlimited code guesses.
+	if factor != nil {
+		return a.mfaChallenge(u.ID, []string{domain.AMRPassword})
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.jwtSecret))
+	tokens, err := a.startSession(ctx, u.ID, meta, []string{domain.AMRPassword})
+	if err != nil {
+		return nil, err
+	}
+	attempt.succeeded(ctx)
+	return &domain.LoginResult{Tokens: tokens}, nil
 }
 
 func (a authService) Register(ctx context.Context, user domain.User) error {
+	if !a.cfg.AllowRegistration {
+		return domain.ErrRegistrationDisabled
+	}
+
+	user.Email = strings.TrimSpace(user.Email)
+	user.FullName = strings.TrimSpace(user.FullName)
+
+	invalid := &httputil.ValidationError{}
+	if _, err := netmail.ParseAddress(user.Email); err != nil {
+		invalid.Add("email", "must be a valid email address")
+	}
+	if user.FullName == "" {
+		invalid.Add("full_name", "is required")
+	}
+	if problems := a.cfg.PasswordPolicy.Validate(user.PasswordHash, user.Email, user.FullName); len(problems) > 0 {
+		invalid.Add("password", problems...)
+	}
+	if err := invalid.OrNil(); err != nil {
+		return err
+	}
+
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.PasswordHash), bcrypt.DefaultCost)
+	hashedPassword, err := a.cfg.Hasher.Hash(user.PasswordHash)
 	if err != nil {
 		return err
 	}
 
-	user.PasswordHash = string(hashedPassword)
-	return a.repo.CreateUser(ctx, &user)
-}
+	user.PasswordHash = hashedPassword
+	user.ActivatedAt = nil
+	if err := a.repo.CreateUser(ctx, &user); err != nil {
+		return err
+	}
 
-func (a authService) RegisterModulePermissions(ctx context.Context, module string, permissions []string) error {
-	var perms []domain.Permission
-	for _, p := range permissions {
-		perms = append(perms, domain.Permission{
-			ID:     p,
-			Module: module,
-		})
+	if err := a.sendVerificationEmail(ctx, &user); err != nil {
+		// The account exists at this point; the user can ask for another link.
+		slog.Error("failed to send verification email", "user_id", user.ID, "error", err)
 	}
-	return a.repo.UpsertPermissions(ctx, perms)
+
+	a.publish(ctx, events.AuthUserRegistered, events.AuthUserRegisteredData{
+		UserID:   user.ID,
+		Email:    user.Email,
+		FullName: user.FullName,
+	})
+	return nil
 }
 
 func (a authService) RegisterModuleMenus(ctx context.Context, domainName string, defs []domain.MenuDefinition) error {
@@ -87,41 +189,75 @@ func (a authService) RegisterModuleMenus(ctx context.Context, domainName string,
 		slog.Error("failed to register module menus", "domain", domainName, "error", err)
 		return err
 	}
+	a.invalidateAccess(ctx, domain.CacheInvalidation{Menus: true})
 	slog.Info("module menus registered", "domain", domainName, "count", len(defs))
 	return nil
 }
 
-func (a authService) CreateRole(ctx context.Context, name string) (*domain.Role, error) {
-	return a.repo.CreateRole(ctx, name)
-}
-
-func (a authService) GetRoles(ctx context.Context) ([]domain.Role, error) {
-	return a.repo.GetRoles(ctx)
-}
-
-func (a authService) AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error {
-	return a.repo.AssignRoleToUser(ctx, userID, roleID)
+func (a authService) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	access, err := a.userAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return access.permissions, nil
 }
 
-func (a authService) AddPermissionToRole(ctx context.Context, roleID int, permissionID string) error {
-	return a.repo.AddPermissionToRole(ctx, roleID, permissionID)
+func (a authService) GetUserRoleIDs(ctx context.Context, userID uuid.UUID) ([]int, error) {
+	access, err := a.userAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return access.roleIDs, nil
 }
 
 func (a authService) GetMyMenu(ctx context.Context, userID uuid.UUID) ([]domain.MenuNode, error) {
-	perms, err := a.repo.GetUserPermissions(ctx, userID)
+	access, err := a.userAccess(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	permMap := make(map[string]bool)
-	for _, p := range perms {
-		permMap[p] = true
+	defs, err := a.cache.menuDefinitions(ctx, a.repo.GetMenuDefinitions)
+	if err != nil {
+		return nil, err
 	}
 
-	defs, err := a.repo.GetMenuDefinitions(ctx)
+	return buildMenuTree(defs, authz.NewGrants(access.permissions)), nil
+}
+
+// publish emits an event on a best-effort basis; the state change it describes has already been persisted.
+// The audit metadata of ctx travels in the message headers.
+func (a authService) publish(ctx context.Context, subject string, data any) {
+	payload, err := json.Marshal(data)
 	if err != nil {
-		return nil, err
+		slog.Error("failed to marshal event", "subject", subject, "error", err)
+		return
 	}
+	err = nats.ErrInvalidConnection
+	if a.nc != nil {
+		err = a.nc.PublishMsg(audit.NewMsg(ctx, subject, payload))
+	}
+	if err != nil {
+		slog.Error("failed to publish event", "subject", subject, "error", err)
+	}
+}
 
-	return buildMenuTree(defs, permMap), nil
+// backgroundTimeout bounds work started by inBackground.
+const backgroundTimeout = time.Minute
+
+// inBackground runs fn after the request has been answered, for work whose duration would reveal
+// something to the caller, such as whether an account exists. fn keeps the request's values but not
+// its cancellation.
+func (a authService) inBackground(ctx context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundTimeout)
+	go func() {
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// record writes an audit entry for actions that publish no event.
+func (a authService) record(ctx context.Context, entry audit.Entry) {
+	if a.cfg.Audit != nil {
+		a.cfg.Audit.Record(ctx, entry)
+	}
 }
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// BootstrapAdmin makes sure an activated account with input.Email exists and holds the Administrator
+// role, and that the role grants "*" and nothing else. It is safe to run repeatedly: an existing account
+// keeps its password, and an archived one is reported rather than restored.
+func (a authService) BootstrapAdmin(ctx context.Context, input domain.BootstrapAdmin) (*domain.BootstrapAdminResult, error) {
+	result := &domain.BootstrapAdminResult{}
+
+	user, created, err := a.ensureAdminUser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.UserID, result.UserCreated = user.ID, created
+
+	role, created, err := a.ensureAdminRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.RoleID, result.RoleCreated = role.ID, created
+
+	if _, _, err := a.SetRolePermissions(ctx, role.ID, []string{authz.Wildcard}); err != nil {
+		return nil, err
+	}
+	if err := a.AssignRole(ctx, user.ID, role.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a authService) ensureAdminUser(ctx context.Context, input domain.BootstrapAdmin) (*domain.User, bool, error) {
+	email := strings.TrimSpace(input.Email)
+	if _, err := netmail.ParseAddress(email); err != nil {
+		return nil, false, httputil.NewValidationError("email", "must be a valid email address")
+	}
+
+	user, err := a.repo.GetUserByEmail(ctx, email)
+	if err == nil {
+		if user.ArchivedAt != nil {
+			return nil, false, domain.ErrAccountArchived
+		}
+		if user.ActivatedAt == nil {
+			if _, err := a.repo.ActivateUser(ctx, user.ID); err != nil {
+				return nil, false, err
+			}
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, httputil.ErrNotFound) {
+		return nil, false, err
+	}
+
+	user = &domain.User{ID: uuid.New(), Email: email, FullName: strings.TrimSpace(input.FullName)}
+	if user.FullName == "" {
+		return nil, false, httputil.NewValidationError("full_name", "is required")
+	}
+	if err := a.validatePassword("password", input.Password, user.Email, user.FullName); err != nil {
+		return nil, false, err
+	}
+	hash, err := a.cfg.Hasher.Hash(input.Password)
+	if err != nil {
+		return nil, false, err
+	}
+	user.PasswordHash = hash
+	// The operator vouches for the address, so there is no verification email.
+	now := time.Now()
+	user.ActivatedAt = &now
+
+	if err := a.repo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, httputil.ErrConflict) {
+			// Another run created the account concurrently.
+			user, err = a.repo.GetUserByEmail(ctx, email)
+			return user, false, err
+		}
+		return nil, false, err
+	}
+
+	slog.Info("administrator account created", "user_id", user.ID)
+	a.publish(ctx, events.AuthUserRegistered, events.AuthUserRegisteredData{
+		UserID:   user.ID,
+		Email:    user.Email,
+		FullName: user.FullName,
+	})
+	return user, true, nil
+}
+
+func (a authService) ensureAdminRole(ctx context.Context) (*domain.Role, bool, error) {
+	role, err := a.repo.GetRoleByName(ctx, domain.AdministratorRole)
+	if err == nil || !errors.Is(err, httputil.ErrNotFound) {
+		return role, false, err
+	}
+
+	role, err = a.CreateRole(ctx, domain.AdministratorRole)
+	if errors.Is(err, httputil.ErrConflict) {
+		role, err = a.repo.GetRoleByName(ctx, domain.AdministratorRole)
+		return role, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return role, true, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// accessCache keeps each user's effective permissions and role IDs, and the menu definitions, in
+// memory. Entries are dropped by invalidation messages and expire after ttl in case one is missed.
+// Cached slices are shared and must not be modified. A nil cache, or a zero ttl, loads from the
+// repository every time.
+type accessCache struct {
+	ttl time.Duration
+
+	mu sync.Mutex
+	// generation is bumped by every invalidation. Loads that started before it are not stored,
+	// so a change racing a load cannot leave a stale entry behind.
+	generation uint64
+	users      map[uuid.UUID]userAccess
+	menus      []domain.MenuDefinition
+	menusUntil time.Time
+}
+
+type userAccess struct {
+	permissions []string
+	roleIDs     []int
+	until       time.Time
+}
+
+func newAccessCache(ttl time.Duration) *accessCache {
+	return &accessCache{ttl: ttl, users: make(map[uuid.UUID]userAccess)}
+}
+
+func (c *accessCache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+func (c *accessCache) user(ctx context.Context, userID uuid.UUID, load func(context.Context, uuid.UUID) (userAccess, error)) (userAccess, error) {
+	if !c.enabled() {
+		return load(ctx, userID)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.users[userID]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.until) {
+		return entry, nil
+	}
+
+	entry, err := load(ctx, userID)
+	if err != nil {
+		return userAccess{}, err
+	}
+	entry.until = time.Now().Add(c.ttl)
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.users[userID] = entry
+	}
+	c.mu.Unlock()
+	return entry, nil
+}
+
+func (c *accessCache) menuDefinitions(ctx context.Context, load func(context.Context) ([]domain.MenuDefinition, error)) ([]domain.MenuDefinition, error) {
+	if !c.enabled() {
+		return load(ctx)
+	}
+
+	c.mu.Lock()
+	defs, until := c.menus, c.menusUntil
+	generation := c.generation
+	c.mu.Unlock()
+	if defs != nil && time.Now().Before(until) {
+		return defs, nil
+	}
+
+	defs, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.menus, c.menusUntil = defs, time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	return defs, nil
+}
+
+func (c *accessCache) apply(inv domain.CacheInvalidation) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if inv.AllUsers {
+		clear(c.users)
+	}
+	for _, id := range inv.UserIDs {
+		delete(c.users, id)
+	}
+	if inv.Menus {
+		c.menus = nil
+	}
+}
+
+// ApplyCacheInvalidation drops the cached entries named by an invalidation message. Replicas receive
+// their own messages too, which is harmless.
+func (a authService) ApplyCacheInvalidation(inv domain.CacheInvalidation) {
+	a.cache.apply(inv)
+}
+
+// invalidateAccess drops the entries on this replica right away, so the caller reads its own write,
+// and tells the other replicas to do the same.
+func (a authService) invalidateAccess(ctx context.Context, inv domain.CacheInvalidation) {
+	a.cache.apply(inv)
+	a.publish(ctx, events.AuthCacheInvalidate, events.AuthCacheInvalidateData{
+		UserIDs:  inv.UserIDs,
+		AllUsers: inv.AllUsers,
+		Menus:    inv.Menus,
+	})
+}
+
+func (a authService) userAccess(ctx context.Context, userID uuid.UUID) (userAccess, error) {
+	return a.cache.user(ctx, userID, func(ctx context.Context, userID uuid.UUID) (userAccess, error) {
+		perms, err := a.repo.GetUserPermissions(ctx, userID)
+		if err != nil {
+			return userAccess{}, err
+		}
+		roleIDs, err := a.repo.GetUserRoleIDs(ctx, userID)
+		if err != nil {
+			return userAccess{}, err
+		}
+		return userAccess{permissions: perms, roleIDs: roleIDs}, nil
+	})
+}
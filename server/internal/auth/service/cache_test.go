@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+func TestAccessCacheInvalidation(t *testing.T) {
+	cache := newAccessCache(time.Minute)
+	alice, bob := uuid.New(), uuid.New()
+
+	loads := map[uuid.UUID]int{}
+	load := func(_ context.Context, id uuid.UUID) (userAccess, error) {
+		loads[id]++
+		return userAccess{permissions: []string{"cms.page.read"}}, nil
+	}
+	get := func(id uuid.UUID) {
+		t.Helper()
+		if _, err := cache.user(context.Background(), id, load); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+
+	get(alice)
+	get(alice)
+	get(bob)
+	if loads[alice] != 1 || loads[bob] != 1 {
+		t.Fatalf("expected one load per user, got %v", loads)
+	}
+
+	cache.apply(domain.CacheInvalidation{UserIDs: []uuid.UUID{alice}})
+	get(alice)
+	get(bob)
+	if loads[alice] != 2 || loads[bob] != 1 {
+		t.Fatalf("expected only alice to be reloaded, got %v", loads)
+	}
+
+	cache.apply(domain.CacheInvalidation{AllUsers: true})
+	get(alice)
+	get(bob)
+	if loads[alice] != 3 || loads[bob] != 2 {
+		t.Fatalf("expected every user to be reloaded, got %v", loads)
+	}
+}
+
+func TestAccessCacheDropsLoadRacingInvalidation(t *testing.T) {
+	cache := newAccessCache(time.Minute)
+	id := uuid.New()
+
+	loads := 0
+	racing := func(_ context.Context, _ uuid.UUID) (userAccess, error) {
+		loads++
+		if loads == 1 {
+			// A role changes while the permissions are being read.
+			cache.apply(domain.CacheInvalidation{UserIDs: []uuid.UUID{id}})
+		}
+		return userAccess{}, nil
+	}
+
+	for range 2 {
+		if _, err := cache.user(context.Background(), id, racing); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("expected the racing load not to be cached, got %d loads", loads)
+	}
+}
+
+func TestAccessCacheDisabled(t *testing.T) {
+	var cache *accessCache
+	loads := 0
+	load := func(_ context.Context) ([]domain.MenuDefinition, error) {
+		loads++
+		return nil, nil
+	}
+	for range 2 {
+		if _, err := cache.menuDefinitions(context.Background(), load); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+	cache.apply(domain.CacheInvalidation{Menus: true})
+	if loads != 2 {
+		t.Fatalf("expected a nil cache to load every time, got %d loads", loads)
+	}
+}
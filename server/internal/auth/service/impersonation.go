@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// StartImpersonation opens a short session for userID on behalf of actorID. The session carries the
+// authentication methods of the actor's own session and cannot be refreshed. Users holding a permission
+// the actor lacks cannot be impersonated, except for the ones impersonation sessions never exercise.
+func (a authService) StartImpersonation(ctx context.Context, actorID, actorSessionID, userID uuid.UUID, meta domain.SessionMeta) (*domain.TokenPair, error) {
+	if actorID == userID {
+		return nil, domain.ErrImpersonateSelf
+	}
+
+	actorSession, err := a.repo.GetSession(ctx, actorSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if target.ArchivedAt != nil {
+		return nil, domain.ErrAccountArchived
+	}
+	if target.ActivatedAt == nil {
+		return nil, domain.ErrAccountNotActivated
+	}
+
+	actorPerms, err := a.GetUserPermissions(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	targetPerms, err := a.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	granted := authz.NewGrants(actorPerms)
+	for _, p := range targetPerms {
+		if !granted.Has(p) && !slices.Contains(domain.ImpersonationDenied, p) {
+			return nil, domain.ErrImpersonationEscalation
+		}
+	}
+
+	session := &domain.Session{
+		ID:             uuid.New(),
+		UserID:         userID,
+		UserAgent:      meta.UserAgent,
+		IPAddress:      meta.IPAddress,
+		AMR:            actorSession.AMR,
+		ExpiresAt:      time.Now().Add(a.cfg.ImpersonationTTL),
+		ImpersonatorID: &actorID,
+	}
+	if err := a.repo.CreateSession(ctx, session, nil); err != nil {
+		return nil, err
+	}
+	tokens, err := a.issueTokenPair(ctx, session, "")
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("impersonation started", "session_id", session.ID, "actor_id", actorID, "user_id", userID)
+	a.publish(ctx, events.AuthImpersonationStarted, events.AuthImpersonationStartedData{
+		SessionID: session.ID,
+		ActorID:   actorID,
+		UserID:    userID,
+		ExpiresAt: session.ExpiresAt,
+	})
+	return tokens, nil
+}
+
+// StopImpersonation ends the impersonation session the claims belong to.
+func (a authService) StopImpersonation(ctx context.Context, claims *domain.UserClaims) error {
+	if !claims.IsImpersonated() {
+		return domain.ErrNotImpersonating
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return httputil.ErrUnauthorized
+	}
+	actorID, err := uuid.Parse(claims.Act.Subject)
+	if err != nil {
+		return httputil.ErrUnauthorized
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return httputil.ErrUnauthorized
+	}
+
+	if err := a.repo.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	slog.Info("impersonation stopped", "session_id", sessionID, "actor_id", actorID, "user_id", userID)
+	a.publish(ctx, events.AuthImpersonationStopped, events.AuthImpersonationStoppedData{
+		SessionID: sessionID,
+		ActorID:   actorID,
+		UserID:    userID,
+	})
+	return nil
+}
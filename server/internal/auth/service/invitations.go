@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	netmail "net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/mail"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// CreateInvitation mails a single-use link that creates an account holding the given roles. Inviting
+// the same email again replaces the pending invitation.
+func (a authService) CreateInvitation(ctx context.Context, actorID uuid.UUID, input domain.NewInvitation) (*domain.Invitation, error) {
+	email := strings.TrimSpace(input.Email)
+	roleIDs := slices.Compact(slices.Sorted(slices.Values(input.RoleIDs)))
+
+	invalid := &httputil.ValidationError{}
+	if _, err := netmail.ParseAddress(email); err != nil {
+		invalid.Add("email", "must be a valid email address")
+	}
+	for _, id := range roleIDs {
+		if _, err := a.repo.GetRole(ctx, id); err != nil {
+			if !errors.Is(err, httputil.ErrNotFound) {
+				return nil, err
+			}
+			invalid.Add("role_ids", fmt.Sprintf("role %d does not exist", id))
+		}
+	}
+	if err := invalid.OrNil(); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.repo.GetUserByEmail(ctx, email); err == nil {
+		return nil, domain.ErrEmailTaken
+	} else if !errors.Is(err, httputil.ErrNotFound) {
+		return nil, err
+	}
+
+	raw, hash, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &domain.Invitation{
+		ID:        uuid.New(),
+		Email:     email,
+		FullName:  strings.TrimSpace(input.FullName),
+		RoleIDs:   roleIDs,
+		TokenHash: hash,
+		InvitedBy: &actorID,
+		ExpiresAt: time.Now().Add(a.cfg.InvitationTTL),
+	}
+	if err := a.repo.CreateInvitation(ctx, inv); err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			// A role was deleted after it was checked.
+			return nil, httputil.NewValidationError("role_ids", "a role no longer exists")
+		}
+		return nil, err
+	}
+
+	a.publish(ctx, events.AuthInvitationCreated, events.AuthInvitationCreatedData{
+		InvitationID: inv.ID,
+		Email:        inv.Email,
+		RoleIDs:      inv.RoleIDs,
+		InvitedBy:    actorID,
+		ExpiresAt:    inv.ExpiresAt,
+	})
+
+	// Unlike verification emails, nobody can ask for this link again, so the sender is told.
+	err = a.cfg.Mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: "You have been invited to the backoffice",
+		Body: fmt.Sprintf(
+			"Hi,\n\nYou have been invited to create a backoffice account. The link expires in %s.\n\n%s/accept-invitation?token=%s\n",
+			a.cfg.InvitationTTL, a.cfg.AppURL, raw,
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("send invitation email: %w", err)
+	}
+	return inv, nil
+}
+
+func (a authService) ListInvitations(ctx context.Context) ([]domain.Invitation, error) {
+	return a.repo.ListPendingInvitations(ctx)
+}
+
+func (a authService) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	inv, err := a.repo.RevokeInvitation(ctx, id)
+	if err != nil {
+		return err
+	}
+	a.publish(ctx, events.AuthInvitationRevoked, events.AuthInvitationRevokedData{InvitationID: inv.ID, Email: inv.Email})
+	return nil
+}
+
+// AcceptInvitation creates the invited account with the password the invitee chose. The invitation
+// email proves the address, so the account is active straight away and holds the invited roles.
+func (a authService) AcceptInvitation(ctx context.Context, input domain.InvitationAcceptance) error {
+	hash := hashToken(input.Token)
+	inv, err := a.repo.GetPendingInvitation(ctx, hash)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	fullName := strings.TrimSpace(input.FullName)
+	if fullName == "" {
+		fullName = inv.FullName
+	}
+	invalid := &httputil.ValidationError{}
+	if fullName == "" {
+		invalid.Add("full_name", "is required")
+	}
+	if problems := a.cfg.PasswordPolicy.Validate(input.Password, inv.Email, fullName); len(problems) > 0 {
+		invalid.Add("password", problems...)
+	}
+	if err := invalid.OrNil(); err != nil {
+		return err
+	}
+
+	passwordHash, err := a.cfg.Hasher.Hash(input.Password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        inv.Email,
+		FullName:     fullName,
+		PasswordHash: passwordHash,
+		ActivatedAt:  &now,
+	}
+
+	roleIDs, err := a.repo.AcceptInvitation(ctx, hash, user)
+	switch {
+	case errors.Is(err, httputil.ErrNotFound):
+		return domain.ErrInvalidToken
+	case errors.Is(err, httputil.ErrConflict):
+		return domain.ErrEmailTaken
+	case err != nil:
+		return err
+	}
+	slog.Info("invitation accepted", "invitation_id", inv.ID, "user_id", user.ID)
+
+	a.publish(ctx, events.AuthUserRegistered, events.AuthUserRegisteredData{
+		UserID:   user.ID,
+		Email:    user.Email,
+		FullName: user.FullName,
+	})
+	for _, roleID := range roleIDs {
+		a.publish(ctx, events.AuthUserRoleAssigned, events.AuthUserRoleAssignedData{UserID: user.ID, RoleID: roleID})
+	}
+	a.publish(ctx, events.AuthInvitationAccepted, events.AuthInvitationAcceptedData{
+		InvitationID: inv.ID,
+		UserID:       user.ID,
+		Email:        user.Email,
+		RoleIDs:      roleIDs,
+	})
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/password"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// invitationRepo implements the repository calls made when an invitation is accepted.
+type invitationRepo struct {
+	domain.Repository
+	pending  map[string]*domain.Invitation
+	accepted []*domain.User
+}
+
+func (r *invitationRepo) GetPendingInvitation(_ context.Context, hash string) (*domain.Invitation, error) {
+	if inv, ok := r.pending[hash]; ok {
+		return inv, nil
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *invitationRepo) AcceptInvitation(_ context.Context, hash string, user *domain.User) ([]int, error) {
+	inv, ok := r.pending[hash]
+	if !ok {
+		return nil, httputil.ErrNotFound
+	}
+	delete(r.pending, hash)
+	r.accepted = append(r.accepted, user)
+	return inv.RoleIDs, nil
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	repo := &invitationRepo{pending: map[string]*domain.Invitation{
+		hashToken("invite"): {ID: uuid.New(), Email: "ana@example.com", FullName: "Ana", RoleIDs: []int{2}, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm:  password.AlgorithmArgon2id,
+		BcryptCost: 4,
+		Argon2id:   password.Argon2id{Memory: 64, Iterations: 1, Parallelism: 1},
+	})
+	if err != nil {
+		t.Fatalf("new hasher: %v", err)
+	}
+	svc := authService{repo: repo, cfg: Config{Hasher: hasher}}
+	ctx := context.Background()
+
+	if err := svc.AcceptInvitation(ctx, domain.InvitationAcceptance{Token: "invite", Password: "correct horse"}); err != nil {
+		t.Fatalf("accept invitation: %v", err)
+	}
+	user := repo.accepted[0]
+	if user.Email != "ana@example.com" || user.FullName != "Ana" || user.ActivatedAt == nil {
+		t.Fatalf("unexpected user: %#v", user)
+	}
+
+	err = svc.AcceptInvitation(ctx, domain.InvitationAcceptance{Token: "invite", Password: "correct horse"})
+	if !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected a used invitation to be rejected, got %v", err)
+	}
+}
+
+func TestRegisterDisabled(t *testing.T) {
+	svc := authService{cfg: Config{AllowRegistration: false}}
+	err := svc.Register(context.Background(), domain.User{Email: "ana@example.com", FullName: "Ana", PasswordHash: "correct horse"})
+	if !errors.Is(err, domain.ErrRegistrationDisabled) {
+		t.Fatalf("expected registration to be disabled, got %v", err)
+	}
+}
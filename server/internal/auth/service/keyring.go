@@ -0,0 +1,187 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+const minRSAKeyBits = 2048
+
+// signingKey is a single entry of the key ring. Retiring keys only carry the public half.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeyRing holds the key used to sign new tokens and every key tokens may still be verified with.
+// Rotating keys is a two-step rollout: ship the new key as retiring so every replica can verify it,
+// then make it active. The old key stays in the ring until the last token it signed has expired.
+type KeyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// LoadKeyRing reads every *.pem file in dir. The file name without its extension is the key ID.
+// Files may hold a PKCS#8 or PKCS#1 private key, or a PKIX public key for keys that are only kept
+// for verification. activeKID selects the signing key; it may be empty when dir holds a single private key.
+func LoadKeyRing(dir, activeKID string) (*KeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+	sort.Strings(paths)
+
+	ring := &KeyRing{keys: make(map[string]*signingKey)}
+	var privateKIDs []string
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: read %s: %w", path, err)
+		}
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: %s: %w", path, err)
+		}
+		ring.keys[kid] = key
+		if key.private != nil {
+			privateKIDs = append(privateKIDs, kid)
+		}
+	}
+
+	if activeKID == "" {
+		if len(privateKIDs) != 1 {
+			return nil, fmt.Errorf("keyring: %d private keys found in %q, set the signing key ID explicitly", len(privateKIDs), dir)
+		}
+		activeKID = privateKIDs[0]
+	}
+
+	active, ok := ring.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("keyring: signing key %q not found in %q", activeKID, dir)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("keyring: signing key %q has no private key", activeKID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+// Sign issues a token signed with the active key and carrying its kid header.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	token.Header["kid"] = k.active.kid
+	return token.SignedString(k.active.private)
+}
+
+// Parse verifies a token against the key named by its kid header. Extra options, such as an
+// expected audience, are applied on top of the algorithm allow-list.
+func (k *KeyRing) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append([]jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+	}, opts...)
+	return jwt.ParseWithClaims(tokenString, claims, k.keyFunc, opts...)
+}
+
+func (k *KeyRing) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not use %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWKS returns the public keys of the ring, the active key first.
+func (k *KeyRing) JWKS() domain.JSONWebKeySet {
+	set := domain.JSONWebKeySet{Keys: []domain.JSONWebKey{k.active.jwk()}}
+
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		if kid != k.active.kid {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, k.keys[kid].jwk())
+	}
+	return set
+}
+
+func (s *signingKey) jwk() domain.JSONWebKey {
+	jwk := domain.JSONWebKey{Kid: s.kid, Use: "sig", Alg: s.method.Alg()}
+	switch pub := s.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+func parseSigningKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.private, key.public, key.method = k, &k.PublicKey, jwt.SigningMethodRS256
+	case *rsa.PublicKey:
+		key.public, key.method = k, jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		key.private, key.public, key.method = k, k.Public(), jwt.SigningMethodEdDSA
+	case ed25519.PublicKey:
+		key.public, key.method = k, jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	if pub, ok := key.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA key is %d bits, at least %d are required", pub.N.BitLen(), minRSAKeyBits)
+	}
+	return key, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, kid string, key any, public bool) {
+	t.Helper()
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestKeyRing returns a ring holding a single Ed25519 signing key.
+func newTestKeyRing(t *testing.T) *KeyRing {
+	t.Helper()
+	dir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "test", priv, false)
+
+	ring, err := LoadKeyRing(dir, "")
+	if err != nil {
+		t.Fatalf("load key ring: %v", err)
+	}
+	return ring
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	dir := t.TempDir()
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2024", oldKey, false)
+	writeKey(t, dir, "2025", newKey, false)
+
+	if _, err := LoadKeyRing(dir, ""); err == nil {
+		t.Fatal("expected an error when several private keys exist and none is selected")
+	}
+
+	claims := jwt.RegisteredClaims{Subject: "user", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+
+	before, err := LoadKeyRing(dir, "2024")
+	if err != nil {
+		t.Fatalf("load key ring: %v", err)
+	}
+	oldToken, err := before.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// Rotate: the new key signs, the old one only verifies.
+	_ = os.Remove(filepath.Join(dir, "2024.pem"))
+	writeKey(t, dir, "2024", oldKey.Public(), true)
+	after, err := LoadKeyRing(dir, "2025")
+	if err != nil {
+		t.Fatalf("load rotated key ring: %v", err)
+	}
+
+	newToken, err := after.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	for name, token := range map[string]string{"retiring key": oldToken, "active key": newToken} {
+		if _, err := after.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+			t.Fatalf("%s: expected token to verify: %v", name, err)
+		}
+	}
+
+	if _, err := newTestKeyRing(t).Parse(newToken, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatal("expected a token with an unknown kid to be rejected")
+	}
+
+	jwks := after.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2025" || jwks.Keys[0].Alg != "RS256" || jwks.Keys[1].Crv != "Ed25519" {
+		t.Fatalf("unexpected JWKS: %#v", jwks)
+	}
+}
+
+func TestKeyRingRejectsHMACTokens(t *testing.T) {
+	ring := newTestKeyRing(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "user"})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ring.Parse(signed, &jwt.RegisteredClaims{}); err == nil {
+		t.Fatal("expected HS256 token to be rejected")
+	}
+}
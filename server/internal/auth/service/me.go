@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+)
+
+func (a authService) GetMe(ctx context.Context, userID uuid.UUID) (*domain.Me, error) {
+	u, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := a.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := a.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if perms == nil {
+		perms = []string{}
+	}
+	return &domain.Me{User: *u, Roles: roles, Permissions: perms}, nil
+}
+
+// UpdateMe applies the self-service subset of UpdateUser.
+func (a authService) UpdateMe(ctx context.Context, userID uuid.UUID, update domain.ProfileUpdate) (*domain.User, error) {
+	return a.UpdateUser(ctx, userID, domain.UserUpdate{FullName: update.FullName})
+}
@@ -4,9 +4,10 @@ import (
 	"sort"
 
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 )
 
-func buildMenuTree(defs []domain.MenuDefinition, userPerms map[string]bool) []domain.MenuNode {
+func buildMenuTree(defs []domain.MenuDefinition, userPerms authz.Grants) []domain.MenuNode {
 	childrenByParent := make(map[string][]domain.MenuDefinition)
 	rootKey := ""
 	for _, d := range defs {
@@ -55,9 +56,9 @@ func buildMenuTree(defs []domain.MenuDefinition, userPerms map[string]bool) []do
 	return build(rootKey)
 }
 
-func hasAnyPermission(perms []string, userPerms map[string]bool) bool {
+func hasAnyPermission(perms []string, userPerms authz.Grants) bool {
 	for _, p := range perms {
-		if userPerms[p] {
+		if userPerms.Has(p) {
 			return true
 		}
 	}
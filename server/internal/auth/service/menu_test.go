@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 )
 
 func TestBuildMenuTreeFiltersAndOrders(t *testing.T) {
@@ -37,3 +38,17 @@ func TestBuildMenuTreeFiltersAndOrders(t *testing.T) {
 		t.Fatalf("unexpected menu: %#v", menu)
 	}
 }
+
+func TestBuildMenuTreeHonoursWildcardGrants(t *testing.T) {
+	defs := []domain.MenuDefinition{
+		{ID: "pages", Label: "Pages", Path: "/pages", Order: 10, Permissions: []string{"cms.page.read"}, Visible: true},
+		{ID: "roles", Label: "Roles", Path: "/roles", Order: 20, Permissions: []string{"auth.role.read"}, Visible: true},
+	}
+
+	menu := buildMenuTree(defs, authz.NewGrants([]string{"cms.*"}))
+
+	expected := []domain.MenuNode{{Label: "Pages", Path: "/pages"}}
+	if !reflect.DeepEqual(menu, expected) {
+		t.Fatalf("unexpected menu: %#v", menu)
+	}
+}
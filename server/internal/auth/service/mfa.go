@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const recoveryCodeCount = 10
+
+func (a authService) CompleteMFALogin(ctx context.Context, challengeToken, code string, meta domain.SessionMeta) (*domain.TokenPair, error) {
+	claims := &domain.MFAChallengeClaims{}
+	parsed, err := a.cfg.Keys.Parse(challengeToken, claims, jwt.WithAudience(domain.MFAChallengeAudience))
+	if err != nil || !parsed.Valid {
+		return nil, domain.ErrInvalidToken
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// The account may have changed since the password step.
+	u, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if u.ArchivedAt != nil {
+		return nil, domain.ErrAccountArchived
+	}
+
+	factor, err := a.confirmedMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	attempt, err := a.admitLoginAttempt(ctx, a.loginTargets(u.Email, meta))
+	if err != nil {
+		return nil, err
+	}
+	defer attempt.release(ctx)
+	if err := a.verifySecondFactor(ctx, factor, code); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			attempt.failed(ctx, &userID)
+		}
+		return nil, err
+	}
+
+	amr := claims.AMR
+	if len(amr) == 0 {
+		amr = []string{domain.AMRPassword}
+	}
+	tokens, err := a.startSession(ctx, userID, meta, append(slices.Clone(amr), domain.AMRMFA))
+	if err != nil {
+		return nil, err
+	}
+	attempt.succeeded(ctx)
+	return tokens, nil
+}
+
+func (a authService) GetMFAStatus(ctx context.Context, userID uuid.UUID) (*domain.MFAStatus, error) {
+	factor, err := a.confirmedMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := a.repo.UserRequiresMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MFAStatus{Enabled: factor != nil, Required: required}
+	if status.Enabled {
+		if status.RecoveryCodesRemaining, err = a.repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// EnrollTOTP starts an enrollment with a fresh secret. The factor is only enabled once ConfirmTOTP
+// receives a valid code, so restarting an unfinished enrollment simply replaces the secret.
+func (a authService) EnrollTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	u, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.SavePendingMFA(ctx, userID, secret); err != nil {
+		if errors.Is(err, httputil.ErrConflict) {
+			return nil, domain.ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &domain.TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(a.cfg.MFAIssuer, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables the pending factor and returns the recovery codes, which are only shown once.
+// The session the user confirmed from counts as two-factor authenticated from then on.
+func (a authService) ConfirmTOTP(ctx context.Context, userID, sessionID uuid.UUID, code string) ([]string, error) {
+	factor, err := a.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	var step int64
+	err = a.throttleCodeCheck(ctx, userID, func() error {
+		var ok bool
+		if step, ok = verifyTOTP(factor.Secret, strings.TrimSpace(code), time.Now(), factor.LastUsedStep); !ok {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.ConfirmMFA(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, httputil.ErrConflict) {
+			return nil, domain.ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	if err := a.repo.AddSessionAMR(ctx, sessionID, domain.AMRMFA); err != nil {
+		return nil, err
+	}
+
+	a.publish(ctx, events.AuthUserMFAEnabled, events.AuthUserMFAEnabledData{UserID: userID})
+	return codes, nil
+}
+
+func (a authService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	factor, err := a.confirmedMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if factor == nil {
+		return domain.ErrMFANotEnabled
+	}
+
+	required, err := a.repo.UserRequiresMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFARequiredByRole
+	}
+
+	if err := a.throttleCodeCheck(ctx, userID, func() error { return a.verifySecondFactor(ctx, factor, code) }); err != nil {
+		return err
+	}
+	if err := a.repo.DeleteUserMFA(ctx, userID); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthUserMFADisabled, events.AuthUserMFADisabledData{UserID: userID})
+	return nil
+}
+
+func (a authService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	factor, err := a.confirmedMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, domain.ErrMFANotEnabled
+	}
+	if err := a.throttleCodeCheck(ctx, userID, func() error { return a.verifySecondFactor(ctx, factor, code) }); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (a authService) SetRoleRequireMFA(ctx context.Context, roleID int, required bool) error {
+	if err := a.repo.SetRoleRequireMFA(ctx, roleID, required); err != nil {
+		return err
+	}
+	if role, err := a.repo.GetRole(ctx, roleID); err == nil {
+		a.publish(ctx, events.AuthRoleUpdated, events.AuthRoleUpdatedData{RoleID: role.ID, Name: role.Name, RequireMFA: role.RequireMFA})
+	}
+	return nil
+}
+
+// confirmedMFA returns the user's enabled factor, or nil when there is none or it is still pending.
+func (a authService) confirmedMFA(ctx context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	factor, err := a.repo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if factor.ConfirmedAt == nil {
+		return nil, nil
+	}
+	return factor, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code.
+func (a authService) verifySecondFactor(ctx context.Context, factor *domain.UserMFA, code string) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := verifyTOTP(factor.Secret, code, time.Now(), factor.LastUsedStep); ok {
+		// The conditional update makes concurrent submissions of the same code fail.
+		advanced, err := a.repo.AdvanceMFAStep(ctx, factor.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	used, err := a.repo.ConsumeRecoveryCode(ctx, factor.UserID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// throttleCodeCheck runs verify as a login attempt of the account, so codes submitted from a signed-in
+// session are throttled like those of CompleteMFALogin and a stolen session cannot guess its way to
+// turning the factor off.
+func (a authService) throttleCodeCheck(ctx context.Context, userID uuid.UUID, verify func() error) error {
+	u, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	attempt, err := a.admitLoginAttempt(ctx, a.loginTargets(u.Email, domain.SessionMeta{}))
+	if err != nil {
+		return err
+	}
+	defer attempt.release(ctx)
+
+	if err := verify(); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			attempt.failed(ctx, &userID)
+		}
+		return err
+	}
+	attempt.succeeded(ctx)
+	return nil
+}
+
+// mfaPending reports whether a session opened with amr falls short of a role that requires two-factor authentication.
+func (a authService) mfaPending(ctx context.Context, userID uuid.UUID, amr []string) (bool, error) {
+	if slices.Contains(amr, domain.AMRMFA) {
+		return false, nil
+	}
+	return a.repo.UserRequiresMFA(ctx, userID)
+}
+
+// mfaEnrollmentPending reports whether one of the user's roles requires a second factor the user has
+// not confirmed yet. Unlike mfaPending it does not depend on how a session was authenticated.
+func (a authService) mfaEnrollmentPending(ctx context.Context, userID uuid.UUID) (bool, error) {
+	required, err := a.repo.UserRequiresMFA(ctx, userID)
+	if err != nil || !required {
+		return false, err
+	}
+	factor, err := a.confirmedMFA(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return factor == nil, nil
+}
+
+// mfaChallenge answers a first authentication step with a challenge for the second factor. amr lists
+// the methods verified so far.
+func (a authService) mfaChallenge(userID uuid.UUID, amr []string) (*domain.LoginResult, error) {
+	challenge, err := a.issueMFAChallenge(userID, amr)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{
+		ChallengeToken: challenge,
+		ChallengeTTL:   int(a.cfg.MFAChallengeTTL.Seconds()),
+	}, nil
+}
+
+func (a authService) issueMFAChallenge(userID uuid.UUID, amr []string) (string, error) {
+	now := time.Now()
+	return a.cfg.Keys.Sign(domain.MFAChallengeClaims{
+		AMR: amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{domain.MFAChallengeAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.cfg.MFAChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	})
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes together with the hashes to persist.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = hashToken(normalizeRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// StartOIDCLogin stores a pending login and returns the provider URL to send the user to.
+func (a authService) StartOIDCLogin(ctx context.Context) (*domain.OIDCAuthorization, error) {
+	if a.cfg.OIDC == nil {
+		return nil, domain.ErrOIDCNotConfigured
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.repo.CreateOIDCState(ctx, &domain.OIDCState{
+		StateHash:    hashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(a.cfg.OIDCStateTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := a.cfg.OIDC.AuthCodeURL(ctx, state, nonce, oidc.S256Challenge(verifier))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OIDCAuthorization{URL: url, ExpiresIn: int(a.cfg.OIDCStateTTL.Seconds()), State: state}, nil
+}
+
+// CompleteOIDCLogin redeems the code the provider redirected back with and opens a session for the
+// linked user. The password check does not apply, as the provider vouches for the user. Users with a
+// confirmed local factor still get a challenge for it unless the provider asserts it verified one.
+// browserState is the state kept by the browser that started the login. Requiring it to match keeps
+// anyone from completing their own login in someone else's browser.
+func (a authService) CompleteOIDCLogin(ctx context.Context, code, state, browserState string, meta domain.SessionMeta) (*domain.LoginResult, error) {
+	if a.cfg.OIDC == nil {
+		return nil, domain.ErrOIDCNotConfigured
+	}
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, domain.ErrOIDCStateMismatch
+	}
+
+	pending, err := a.repo.ConsumeOIDCState(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	claims, err := a.cfg.OIDC.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		slog.Warn("oidc login rejected", "error", err)
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	u, err := a.linkedUser(ctx, claims)
+	if err == nil && u.ArchivedAt != nil {
+		err = domain.ErrAccountArchived
+	}
+	if err != nil {
+		a.record(ctx, audit.Entry{
+			Action:     domain.AuditLoginFailed,
+			TargetType: domain.AuditTargetUser,
+			Changes:    map[string]string{"method": "oidc", "subject": claims.Subject, "email": claims.Email},
+		})
+		return nil, err
+	}
+
+	// The provider has verified the address, which is what activation is waiting for.
+	if u.ActivatedAt == nil {
+		activated, err := a.repo.ActivateUser(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		if activated {
+			a.publish(ctx, events.AuthUserActivated, events.AuthUserActivatedData{UserID: u.ID})
+		}
+	}
+
+	if err := a.syncGroupRoles(ctx, u.ID, claims.Groups); err != nil {
+		return nil, err
+	}
+
+	amr := []string{domain.AMRFederated}
+	if slices.Contains(claims.AMR, domain.AMRMFA) {
+		amr = append(amr, domain.AMRMFA)
+	} else {
+		factor, err := a.confirmedMFA(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		if factor != nil {
+			return a.mfaChallenge(u.ID, amr)
+		}
+	}
+
+	tokens, err := a.startSession(ctx, u.ID, meta, amr)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: tokens}, nil
+}
+
+// linkedUser returns the user linked to the provider account, linking it on first use to the user
+// with the same email when the provider has verified that address.
+func (a authService) linkedUser(ctx context.Context, claims *oidc.Claims) (*domain.User, error) {
+	identity, err := a.repo.GetUserIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		if err := a.repo.TouchUserIdentity(ctx, identity.ID, claims.Email); err != nil {
+			return nil, err
+		}
+		return a.repo.GetUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, httputil.ErrNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, domain.ErrOIDCEmailNotVerified
+	}
+	u, err := a.repo.GetUserByEmail(ctx, claims.Email)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, domain.ErrOIDCAccountNotFound
+		}
+		return nil, err
+	}
+
+	identity = &domain.UserIdentity{
+		ID:      uuid.New(),
+		UserID:  u.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	}
+	if err := a.repo.CreateUserIdentity(ctx, identity); err != nil {
+		if errors.Is(err, httputil.ErrConflict) {
+			return nil, domain.ErrOIDCIdentityConflict
+		}
+		return nil, err
+	}
+	a.publish(ctx, events.AuthUserIdentityLinked, events.AuthUserIdentityLinkedData{
+		UserID:  u.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	})
+	return u, nil
+}
+
+// syncGroupRoles makes the roles named in the group mapping follow the user's provider groups on
+// every login. Roles the mapping does not mention are left alone, so roles granted by hand remain.
+func (a authService) syncGroupRoles(ctx context.Context, userID uuid.UUID, groups []string) error {
+	if len(a.cfg.OIDCGroupRoles) == 0 {
+		return nil
+	}
+
+	current, err := a.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	assigned := make(map[string]int, len(current))
+	for _, role := range current {
+		assigned[role.Name] = role.ID
+	}
+
+	for name, wanted := range mappedRoles(a.cfg.OIDCGroupRoles, groups) {
+		roleID, has := assigned[name]
+		switch {
+		case wanted && !has:
+			role, err := a.repo.GetRoleByName(ctx, name)
+			if errors.Is(err, httputil.ErrNotFound) {
+				slog.Warn("oidc group mapping names an unknown role", "role", name)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := a.AssignRole(ctx, userID, role.ID); err != nil {
+				return err
+			}
+		case !wanted && has:
+			if err := a.UnassignRole(ctx, userID, roleID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mappedRoles returns every role named in mapping, true when one of groups maps to it.
+func mappedRoles(mapping map[string]string, groups []string) map[string]bool {
+	roles := make(map[string]bool, len(mapping))
+	for group, role := range mapping {
+		roles[role] = roles[role] || slices.Contains(groups, group)
+	}
+	return roles
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc/oidctest"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// oidcRepo implements the repository calls made by single sign-on logins that publish no event,
+// and those of the second-factor step without any throttling.
+type oidcRepo struct {
+	domain.Repository
+	states     map[string]*domain.OIDCState
+	identities []domain.UserIdentity
+	users      map[uuid.UUID]*domain.User
+	factors    map[uuid.UUID]*domain.UserMFA
+	sessions   []*domain.Session
+}
+
+func (r *oidcRepo) CreateOIDCState(_ context.Context, s *domain.OIDCState) error {
+	r.states[s.StateHash] = s
+	return nil
+}
+
+func (r *oidcRepo) ConsumeOIDCState(_ context.Context, hash string) (*domain.OIDCState, error) {
+	s, ok := r.states[hash]
+	if !ok {
+		return nil, httputil.ErrNotFound
+	}
+	delete(r.states, hash)
+	return s, nil
+}
+
+func (r *oidcRepo) GetUserIdentity(_ context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	for _, i := range r.identities {
+		if i.Issuer == issuer && i.Subject == subject {
+			return &i, nil
+		}
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *oidcRepo) TouchUserIdentity(context.Context, uuid.UUID, string) error { return nil }
+
+func (r *oidcRepo) GetUserByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *oidcRepo) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *oidcRepo) CreateSession(_ context.Context, s *domain.Session, _ *domain.RefreshToken) error {
+	r.sessions = append(r.sessions, s)
+	return nil
+}
+
+func (r *oidcRepo) UserRequiresMFA(context.Context, uuid.UUID) (bool, error) { return false, nil }
+
+func (r *oidcRepo) GetUserMFA(_ context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	if f, ok := r.factors[userID]; ok {
+		return f, nil
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *oidcRepo) AdvanceMFAStep(_ context.Context, userID uuid.UUID, step int64) (bool, error) {
+	r.factors[userID].LastUsedStep = step
+	return true, nil
+}
+
+func (r *oidcRepo) CountLoginAttempt(_ context.Context, scope, key string, _ time.Time, _ func(*domain.LoginThrottle) error) (*domain.LoginThrottle, error) {
+	return &domain.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: time.Now()}, nil
+}
+
+func (r *oidcRepo) ClearLoginThrottle(context.Context, string, string) error { return nil }
+
+func (r *oidcRepo) ReleaseLoginAttempt(context.Context, string, string, time.Time, time.Time) error {
+	return nil
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	srv := oidctest.NewServer("backoffice", "s3cret")
+	defer srv.Close()
+	provider, err := oidc.NewProvider(oidc.Config{
+		IssuerURL:    srv.URL,
+		ClientID:     srv.ClientID,
+		ClientSecret: srv.ClientSecret,
+		RedirectURL:  "http://app.test/auth/callback",
+	})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+
+	now := time.Now()
+	linked := &domain.User{ID: uuid.New(), Email: "ana@example.com", ActivatedAt: &now}
+	unlinked := &domain.User{ID: uuid.New(), Email: "rui@example.com", ActivatedAt: &now}
+	enrolled := &domain.User{ID: uuid.New(), Email: "mia@example.com", ActivatedAt: &now}
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	repo := &oidcRepo{
+		states: map[string]*domain.OIDCState{},
+		identities: []domain.UserIdentity{
+			{ID: uuid.New(), UserID: linked.ID, Issuer: srv.URL, Subject: "ana"},
+			{ID: uuid.New(), UserID: enrolled.ID, Issuer: srv.URL, Subject: "mia"},
+		},
+		users:   map[uuid.UUID]*domain.User{linked.ID: linked, unlinked.ID: unlinked, enrolled.ID: enrolled},
+		factors: map[uuid.UUID]*domain.UserMFA{enrolled.ID: {UserID: enrolled.ID, Secret: secret, ConfirmedAt: &now}},
+	}
+	svc := authService{repo: repo, cfg: Config{
+		Keys:            newTestKeyRing(t),
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		MFAChallengeTTL: time.Minute,
+		OIDC:            provider,
+		OIDCStateTTL:    time.Minute,
+	}}
+	ctx := context.Background()
+
+	login := func(t *testing.T, claims map[string]any) (string, string) {
+		t.Helper()
+		start, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("start login: %v", err)
+		}
+		code, state, err := srv.Login(start.URL, claims)
+		if err != nil {
+			t.Fatalf("provider login: %v", err)
+		}
+		return code, state
+	}
+
+	t.Run("linked identity", func(t *testing.T) {
+		code, state := login(t, map[string]any{"sub": "ana", "email": "ana@example.com", "amr": []string{"pwd", "mfa"}})
+		if _, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{}); err != nil {
+			t.Fatalf("complete login: %v", err)
+		}
+		session := repo.sessions[len(repo.sessions)-1]
+		if session.UserID != linked.ID || !slices.Equal(session.AMR, []string{domain.AMRFederated, domain.AMRMFA}) {
+			t.Fatalf("unexpected session: %#v", session)
+		}
+
+		if _, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{}); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Fatalf("expected a used state to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("state from another browser", func(t *testing.T) {
+		code, state := login(t, map[string]any{"sub": "ana", "email": "ana@example.com"})
+		other, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("start login: %v", err)
+		}
+
+		for _, browserState := range []string{"", other.State} {
+			if _, err := svc.CompleteOIDCLogin(ctx, code, state, browserState, domain.SessionMeta{}); !errors.Is(err, domain.ErrOIDCStateMismatch) {
+				t.Fatalf("expected a callback without the browser's state to be rejected, got %v", err)
+			}
+		}
+		// The mismatch is caught before the state is used up.
+		if _, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{}); err != nil {
+			t.Fatalf("complete login: %v", err)
+		}
+	})
+
+	t.Run("local second factor", func(t *testing.T) {
+		code, state := login(t, map[string]any{"sub": "mia", "email": "mia@example.com", "amr": []string{"pwd"}})
+		sessions := len(repo.sessions)
+		result, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{})
+		if err != nil {
+			t.Fatalf("complete login: %v", err)
+		}
+		if result.Tokens != nil || result.ChallengeToken == "" || len(repo.sessions) != sessions {
+			t.Fatalf("expected a challenge for the local factor instead of a session, got %#v", result)
+		}
+
+		key, err := totpEncoding.DecodeString(secret)
+		if err != nil {
+			t.Fatalf("decode secret: %v", err)
+		}
+		totp := totpCode(key, totpStep(time.Now()), totpDigits)
+		if _, err := svc.CompleteMFALogin(ctx, result.ChallengeToken, totp, domain.SessionMeta{}); err != nil {
+			t.Fatalf("complete second factor: %v", err)
+		}
+		session := repo.sessions[len(repo.sessions)-1]
+		if session.UserID != enrolled.ID || !slices.Equal(session.AMR, []string{domain.AMRFederated, domain.AMRMFA}) {
+			t.Fatalf("expected a federated session with the second factor, got %#v", session)
+		}
+	})
+
+	t.Run("unverified email is not linked", func(t *testing.T) {
+		code, state := login(t, map[string]any{"sub": "rui", "email": "rui@example.com", "email_verified": false})
+		if _, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{}); !errors.Is(err, domain.ErrOIDCEmailNotVerified) {
+			t.Fatalf("expected unverified email to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		code, state := login(t, map[string]any{"sub": "eva", "email": "eva@example.com", "email_verified": true})
+		if _, err := svc.CompleteOIDCLogin(ctx, code, state, state, domain.SessionMeta{}); !errors.Is(err, domain.ErrOIDCAccountNotFound) {
+			t.Fatalf("expected unknown account to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestMappedRoles(t *testing.T) {
+	mapping := map[string]string{"staff": "Editor", "leads": "Editor", "it": "Administrator"}
+
+	got := mappedRoles(mapping, []string{"leads", "sales"})
+	want := map[string]bool{"Editor": true, "Administrator": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
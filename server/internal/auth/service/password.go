@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/mail"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// ForgotPassword mails a reset link when the account exists. It reports success either way so
+// the endpoint cannot be used to find out which emails are registered; the link is issued and mailed
+// in the background so the response time does not tell either.
+func (a authService) ForgotPassword(ctx context.Context, email string) error {
+	u, err := a.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.ArchivedAt != nil {
+		return nil
+	}
+
+	a.inBackground(ctx, func(ctx context.Context) {
+		if err := a.sendPasswordResetEmail(ctx, u); err != nil {
+			slog.Error("failed to send password reset email", "user_id", u.ID, "error", err)
+		}
+	})
+	return nil
+}
+
+// sendPasswordResetEmail replaces any pending reset link with a new one and mails it.
+func (a authService) sendPasswordResetEmail(ctx context.Context, u *domain.User) error {
+	// Only the most recent link works.
+	if err := a.repo.InvalidateUserTokens(ctx, u.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	raw, err := a.issueUserToken(ctx, u.ID, domain.TokenPurposePasswordReset, a.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.cfg.Mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s/reset-password?token=%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			u.FullName, a.cfg.PasswordResetTTL, a.cfg.AppURL, raw,
+		),
+	})
+}
+
+func (a authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Validate before consuming the token so a rejected password does not burn the link.
+	pending, err := a.repo.GetUserToken(ctx, domain.TokenPurposePasswordReset, hashToken(token))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+	u, err := a.repo.GetUserByID(ctx, pending.UserID)
+	if err != nil {
+		return err
+	}
+	if err := a.validatePassword("password", newPassword, u.Email, u.FullName); err != nil {
+		return err
+	}
+
+	t, err := a.repo.ConsumeUserToken(ctx, domain.TokenPurposePasswordReset, hashToken(token))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	hash, err := a.cfg.Hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.UpdatePassword(ctx, t.UserID, hash); err != nil {
+		return err
+	}
+	if err := a.repo.RevokeUserSessions(ctx, t.UserID); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthUserPasswordReset, events.AuthUserPasswordResetData{UserID: t.UserID})
+	a.publish(ctx, events.AuthUserPasswordChanged, events.AuthUserPasswordChangedData{UserID: t.UserID})
+	return nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the current one, then signs
+// out every other session. Wrong current passwords count towards the same throttle as failed logins.
+func (a authService) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string) error {
+	u, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	attempt, err := a.admitLoginAttempt(ctx, a.loginTargets(u.Email, domain.SessionMeta{}))
+	if err != nil {
+		return err
+	}
+	if match, _ := a.verifyPassword(u, currentPassword); !match {
+		attempt.failed(ctx, &u.ID)
+		return httputil.NewValidationError("current_password", "is incorrect")
+	}
+	attempt.succeeded(ctx)
+
+	if err := a.validatePassword("new_password", newPassword, u.Email, u.FullName); err != nil {
+		return err
+	}
+
+	hash, err := a.cfg.Hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return err
+	}
+	if err := a.repo.RevokeOtherUserSessions(ctx, u.ID, sessionID); err != nil {
+		return err
+	}
+	// A reset link requested before the change must not undo it.
+	if err := a.repo.InvalidateUserTokens(ctx, u.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthUserPasswordChanged, events.AuthUserPasswordChangedData{UserID: u.ID})
+	return nil
+}
+
+// validatePassword applies the password policy and reports violations against the given request field.
+func (a authService) validatePassword(field, password, email, fullName string) error {
+	if problems := a.cfg.PasswordPolicy.Validate(password, email, fullName); len(problems) > 0 {
+		return httputil.NewValidationError(field, problems...)
+	}
+	return nil
+}
+
+// verifyPassword checks password against the user's stored hash. needsRehash is set on a match when
+// the hash was made with an outdated algorithm or parameters.
+func (a authService) verifyPassword(u *domain.User, password string) (match, needsRehash bool) {
+	match, needsRehash, err := a.cfg.Hasher.Verify(password, u.PasswordHash)
+	if err != nil {
+		slog.Error("failed to verify password hash", "user_id", u.ID, "error", err)
+		return false, false
+	}
+	return match, needsRehash
+}
+
+// upgradePasswordHash replaces an outdated hash once the password is known to be right, so existing
+// users move to the configured algorithm as they sign in. Failures only delay the upgrade.
+func (a authService) upgradePasswordHash(ctx context.Context, u *domain.User, password string) {
+	hash, err := a.cfg.Hasher.Hash(password)
+	if err == nil {
+		err = a.repo.RehashPassword(ctx, u.ID, u.PasswordHash, hash)
+	}
+	if err != nil {
+		slog.Warn("failed to upgrade password hash", "user_id", u.ID, "error", err)
+	}
+}
+
+// issueUserToken stores a new single-use token and returns the raw value to be mailed.
+func (a authService) issueUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	raw, hash, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	err = a.repo.CreateUserToken(ctx, &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// RegisterModulePermissions treats permissions as the complete declaration of the module: anything the
+// module registered before but no longer declares is deprecated.
+func (a authService) RegisterModulePermissions(ctx context.Context, module string, permissions []domain.PermissionDefinition) error {
+	var perms []domain.Permission
+	for _, p := range permissions {
+		perms = append(perms, domain.Permission{
+			ID:          p.ID,
+			Module:      module,
+			Description: p.Description,
+			Group:       p.Group,
+			Order:       p.Order,
+		})
+	}
+
+	deprecated, err := a.repo.SyncModulePermissions(ctx, module, perms)
+	if err != nil {
+		return err
+	}
+
+	if len(deprecated) > 0 {
+		slog.Warn("module permissions deprecated", "module", module, "permissions", deprecated)
+		a.publish(ctx, events.AuthPermsDeprecated, events.AuthPermsDeprecatedData{Module: module, Permissions: deprecated})
+	}
+	return nil
+}
+
+// GetPermissionCatalogue lists every registered permission, one section per module.
+func (a authService) GetPermissionCatalogue(ctx context.Context) ([]domain.PermissionModule, error) {
+	perms, err := a.repo.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return groupPermissionsByModule(perms), nil
+}
+
+func (a authService) GetDeprecatedPermissions(ctx context.Context) ([]domain.DeprecatedPermission, error) {
+	return a.repo.GetDeprecatedPermissions(ctx)
+}
+
+// PurgeDeprecatedPermissions deletes the deprecated permissions and revokes them from every role.
+func (a authService) PurgeDeprecatedPermissions(ctx context.Context) ([]string, error) {
+	purged, grants, err := a.repo.PurgeDeprecatedPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(purged) == 0 {
+		return purged, nil
+	}
+
+	a.invalidateAccess(ctx, domain.CacheInvalidation{AllUsers: true})
+
+	removed := make(map[int][]string)
+	var roleIDs []int
+	for _, g := range grants {
+		if _, ok := removed[g.RoleID]; !ok {
+			roleIDs = append(roleIDs, g.RoleID)
+		}
+		removed[g.RoleID] = append(removed[g.RoleID], g.PermissionID)
+	}
+	for _, roleID := range roleIDs {
+		a.publish(ctx, events.AuthRolePermsChanged, events.AuthRolePermsChangedData{RoleID: roleID, Added: []string{}, Removed: removed[roleID]})
+	}
+	a.publish(ctx, events.AuthPermsPurged, events.AuthPermsPurgedData{Permissions: purged})
+	return purged, nil
+}
+
+// groupPermissionsByModule expects perms ordered by module and keeps that order.
+func groupPermissionsByModule(perms []domain.Permission) []domain.PermissionModule {
+	modules := []domain.PermissionModule{}
+	for _, p := range perms {
+		if n := len(modules); n == 0 || modules[n-1].Module != p.Module {
+			modules = append(modules, domain.PermissionModule{Module: p.Module})
+		}
+		last := &modules[len(modules)-1]
+		last.Permissions = append(last.Permissions, p)
+	}
+	return modules
+}
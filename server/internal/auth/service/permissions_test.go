@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// permissionRepo keeps the permission registry and role grants in memory. Like role_permissions, the
+// grants are not tied to the registry, so wildcards and purged IDs are only removed by the repository.
+type permissionRepo struct {
+	domain.Repository
+	permissions map[string]*domain.Permission
+	roles       map[int]domain.Role
+	grants      map[int][]string
+}
+
+func (r *permissionRepo) SyncModulePermissions(_ context.Context, module string, perms []domain.Permission) ([]string, error) {
+	declared := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		declared[p.ID] = true
+		r.permissions[p.ID] = &p
+	}
+	var deprecated []string
+	now := time.Now()
+	for id, p := range r.permissions {
+		if p.Module == module && p.DeprecatedAt == nil && !declared[id] {
+			p.DeprecatedAt = &now
+			deprecated = append(deprecated, id)
+		}
+	}
+	return deprecated, nil
+}
+
+func (r *permissionRepo) GetDeprecatedPermissions(context.Context) ([]domain.DeprecatedPermission, error) {
+	perms := []domain.DeprecatedPermission{}
+	for _, p := range r.permissions {
+		if p.DeprecatedAt == nil {
+			continue
+		}
+		dp := domain.DeprecatedPermission{Permission: *p, Roles: []domain.Role{}}
+		for roleID, grants := range r.grants {
+			if slices.Contains(grants, p.ID) {
+				dp.Roles = append(dp.Roles, r.roles[roleID])
+			}
+		}
+		slices.SortFunc(dp.Roles, func(a, b domain.Role) int { return a.ID - b.ID })
+		perms = append(perms, dp)
+	}
+	return perms, nil
+}
+
+func (r *permissionRepo) PurgeDeprecatedPermissions(context.Context) ([]string, []domain.RoleGrant, error) {
+	var ids []string
+	for id, p := range r.permissions {
+		if p.DeprecatedAt != nil {
+			ids = append(ids, id)
+		}
+	}
+	grants := []domain.RoleGrant{}
+	for _, roleID := range slices.Sorted(maps.Keys(r.grants)) {
+		r.grants[roleID] = slices.DeleteFunc(r.grants[roleID], func(id string) bool {
+			if slices.Contains(ids, id) {
+				grants = append(grants, domain.RoleGrant{RoleID: roleID, PermissionID: id})
+				return true
+			}
+			return false
+		})
+	}
+	for _, id := range ids {
+		delete(r.permissions, id)
+	}
+	return ids, grants, nil
+}
+
+func TestDeprecatedPermissionLifecycle(t *testing.T) {
+	repo := &permissionRepo{
+		permissions: map[string]*domain.Permission{
+			"cms.page.read":    {ID: "cms.page.read", Module: "cms"},
+			"cms.page.write":   {ID: "cms.page.write", Module: "cms"},
+			"cms.page.publish": {ID: "cms.page.publish", Module: "cms"},
+			"auth.user.read":   {ID: "auth.user.read", Module: "auth"},
+		},
+		roles: map[int]domain.Role{2: {ID: 2, Name: "Editor"}, 3: {ID: 3, Name: "Publisher"}},
+		grants: map[int][]string{
+			2: {"cms.page.read", "cms.page.publish", "cms.*"},
+			3: {"cms.page.publish", "auth.user.read"},
+		},
+	}
+	published := &eventRecorder{}
+	svc := authService{repo: repo, nc: published, cache: newAccessCache(time.Minute)}
+	ctx := context.Background()
+
+	err := svc.RegisterModulePermissions(ctx, "cms", []domain.PermissionDefinition{{ID: "cms.page.read"}, {ID: "cms.page.write"}})
+	if err != nil {
+		t.Fatalf("register permissions: %v", err)
+	}
+	publish, ok := repo.permissions["cms.page.publish"]
+	if !ok || publish.DeprecatedAt == nil {
+		t.Fatalf("expected the undeclared permission to be kept as deprecated, got %#v", publish)
+	}
+	if !slices.Contains(repo.grants[3], "cms.page.publish") {
+		t.Fatal("expected deprecation to keep the role grants")
+	}
+	if !slices.Equal(published.subjects, []string{events.AuthPermsDeprecated}) || svc.cache.generation != 0 {
+		t.Fatalf("expected only the deprecation to be published, got events %v", published.subjects)
+	}
+
+	report, err := svc.GetDeprecatedPermissions(ctx)
+	if err != nil {
+		t.Fatalf("deprecated permissions: %v", err)
+	}
+	if len(report) != 1 || report[0].ID != "cms.page.publish" {
+		t.Fatalf("expected the deprecated permission to be reported, got %#v", report)
+	}
+	if !slices.Equal(report[0].Roles, []domain.Role{repo.roles[2], repo.roles[3]}) {
+		t.Fatalf("expected the roles still granting it, got %#v", report[0].Roles)
+	}
+
+	published.subjects = nil
+	purged, err := svc.PurgeDeprecatedPermissions(ctx)
+	if err != nil {
+		t.Fatalf("purge permissions: %v", err)
+	}
+	if !slices.Equal(purged, []string{"cms.page.publish"}) {
+		t.Fatalf("expected only the deprecated permission to be purged, got %v", purged)
+	}
+	if len(repo.permissions) != 3 {
+		t.Fatalf("expected the declared permissions to remain, got %v", repo.permissions)
+	}
+	if !slices.Equal(repo.grants[2], []string{"cms.page.read", "cms.*"}) || !slices.Equal(repo.grants[3], []string{"auth.user.read"}) {
+		t.Fatalf("expected only the purged grants to be revoked, got %v", repo.grants)
+	}
+	want := []string{events.AuthCacheInvalidate, events.AuthRolePermsChanged, events.AuthRolePermsChanged, events.AuthPermsPurged}
+	if !slices.Equal(published.subjects, want) || svc.cache.generation != 1 {
+		t.Fatalf("expected the purge to clear the cache and be published, got events %v", published.subjects)
+	}
+
+	published.subjects = nil
+	if purged, err := svc.PurgeDeprecatedPermissions(ctx); err != nil || len(purged) != 0 {
+		t.Fatalf("expected nothing left to purge, got %v (%v)", purged, err)
+	}
+	if len(published.subjects) != 0 || svc.cache.generation != 1 {
+		t.Fatalf("expected an empty purge to publish nothing, got events %v", published.subjects)
+	}
+}
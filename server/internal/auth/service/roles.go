@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+const maxRoleNameLength = 50
+
+func (a authService) CreateRole(ctx context.Context, name string) (*domain.Role, error) {
+	name, err := validateRoleName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := a.repo.CreateRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	a.publish(ctx, events.AuthRoleCreated, events.AuthRoleCreatedData{RoleID: role.ID, Name: role.Name})
+	return role, nil
+}
+
+func (a authService) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	return a.repo.GetRoles(ctx)
+}
+
+func (a authService) GetRoleDetail(ctx context.Context, id int) (*domain.RoleDetail, error) {
+	role, err := a.repo.GetRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := a.repo.GetRolePermissions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	inherited, err := a.repo.GetRoleInheritedPermissions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	parents, err := a.repo.GetRoleParents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := a.repo.GetRoleMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RoleDetail{
+		Role:                 *role,
+		Parents:              parents,
+		Permissions:          perms,
+		InheritedPermissions: inherited,
+		Members:              members,
+	}, nil
+}
+
+func (a authService) RenameRole(ctx context.Context, id int, name string) (*domain.Role, error) {
+	name, err := validateRoleName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := a.repo.RenameRole(ctx, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	a.publish(ctx, events.AuthRoleUpdated, events.AuthRoleUpdatedData{RoleID: role.ID, Name: role.Name, RequireMFA: role.RequireMFA})
+	return role, nil
+}
+
+// DeleteRole removes a role no user holds and no role inherits from anymore; its permission grants go with it.
+func (a authService) DeleteRole(ctx context.Context, id int) error {
+	role, err := a.repo.GetRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.DeleteRole(ctx, id); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthRoleDeleted, events.AuthRoleDeletedData{RoleID: role.ID, Name: role.Name})
+	return nil
+}
+
+// SetRoleParents replaces the roles a role inherits from and returns the new parents.
+func (a authService) SetRoleParents(ctx context.Context, roleID int, parentIDs []int) ([]domain.Role, error) {
+	ids := make([]int, 0, len(parentIDs))
+	seen := make(map[int]bool, len(parentIDs))
+	for _, id := range parentIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if err := a.repo.SetRoleParents(ctx, roleID, ids); err != nil {
+		return nil, err
+	}
+	parents, err := a.repo.GetRoleParents(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.invalidateAccess(ctx, domain.CacheInvalidation{AllUsers: true})
+	a.publish(ctx, events.AuthRoleParentsChanged, events.AuthRoleParentsChangedData{RoleID: roleID, ParentIDs: ids})
+	return parents, nil
+}
+
+func (a authService) AssignRole(ctx context.Context, userID uuid.UUID, roleID int) error {
+	if _, err := a.repo.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+
+	assigned, err := a.repo.AssignRoleToUser(ctx, userID, roleID)
+	if err != nil {
+		return err
+	}
+
+	if assigned {
+		a.invalidateAccess(ctx, domain.CacheInvalidation{UserIDs: []uuid.UUID{userID}})
+		a.publish(ctx, events.AuthUserRoleAssigned, events.AuthUserRoleAssignedData{UserID: userID, RoleID: roleID})
+	}
+	return nil
+}
+
+func (a authService) UnassignRole(ctx context.Context, userID uuid.UUID, roleID int) error {
+	if err := a.repo.UnassignRoleFromUser(ctx, userID, roleID); err != nil {
+		return err
+	}
+
+	a.invalidateAccess(ctx, domain.CacheInvalidation{UserIDs: []uuid.UUID{userID}})
+	a.publish(ctx, events.AuthUserRoleUnassigned, events.AuthUserRoleUnassignedData{UserID: userID, RoleID: roleID})
+	return nil
+}
+
+func (a authService) AddPermissionToRole(ctx context.Context, roleID int, permissionID string) error {
+	if _, err := a.repo.GetRole(ctx, roleID); err != nil {
+		return err
+	}
+
+	if err := a.validateGrants(ctx, []string{permissionID}); err != nil {
+		return err
+	}
+
+	added, err := a.repo.AddPermissionToRole(ctx, roleID, permissionID)
+	if err != nil {
+		return err
+	}
+
+	if added {
+		a.invalidateAccess(ctx, domain.CacheInvalidation{AllUsers: true})
+		a.publish(ctx, events.AuthRolePermsChanged, events.AuthRolePermsChangedData{RoleID: roleID, Added: []string{permissionID}, Removed: []string{}})
+	}
+	return nil
+}
+
+func (a authService) RemovePermissionFromRole(ctx context.Context, roleID int, permissionID string) error {
+	if err := a.repo.RemovePermissionFromRole(ctx, roleID, permissionID); err != nil {
+		return err
+	}
+
+	a.invalidateAccess(ctx, domain.CacheInvalidation{AllUsers: true})
+	a.publish(ctx, events.AuthRolePermsChanged, events.AuthRolePermsChangedData{RoleID: roleID, Added: []string{}, Removed: []string{permissionID}})
+	return nil
+}
+
+// SetRolePermissions replaces the permission set of a role and returns the difference to the previous one.
+func (a authService) SetRolePermissions(ctx context.Context, roleID int, permissionIDs []string) ([]string, []string, error) {
+	ids := make([]string, 0, len(permissionIDs))
+	seen := make(map[string]bool, len(permissionIDs))
+	for _, id := range permissionIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	if err := a.validateGrants(ctx, ids); err != nil {
+		return nil, nil, err
+	}
+
+	added, removed, err := a.repo.SetRolePermissions(ctx, roleID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		// Roles are inherited, so a change reaches more users than the role's own members.
+		a.invalidateAccess(ctx, domain.CacheInvalidation{AllUsers: true})
+		a.publish(ctx, events.AuthRolePermsChanged, events.AuthRolePermsChangedData{RoleID: roleID, Added: added, Removed: removed})
+	}
+	return added, removed, nil
+}
+
+// validateGrants accepts registered permission IDs and wildcards covering at least one of them.
+func (a authService) validateGrants(ctx context.Context, grants []string) error {
+	if len(grants) == 0 {
+		return nil
+	}
+	registered, err := a.repo.GetPermissionIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range grants {
+		if !authz.ValidGrant(g, registered) {
+			return domain.ErrUnknownPermission
+		}
+	}
+	return nil
+}
+
+func validateRoleName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", httputil.NewValidationError("name", "is required")
+	}
+	if utf8.RuneCountInString(name) > maxRoleNameLength {
+		return "", httputil.NewValidationError("name", "must be at most 50 characters")
+	}
+	return name, nil
+}
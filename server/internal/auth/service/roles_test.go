@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// roleRepo keeps roles, their grants, members and children in memory, with the same guards as the
+// Postgres repository.
+type roleRepo struct {
+	domain.Repository
+	roles       map[int]*domain.Role
+	grants      map[int][]string
+	members     map[int][]uuid.UUID
+	children    map[int][]int
+	permissions []string
+}
+
+func (r *roleRepo) GetRole(_ context.Context, id int) (*domain.Role, error) {
+	if role, ok := r.roles[id]; ok {
+		return role, nil
+	}
+	return nil, httputil.ErrNotFound
+}
+
+func (r *roleRepo) DeleteRole(_ context.Context, id int) error {
+	if _, ok := r.roles[id]; !ok {
+		return httputil.ErrNotFound
+	}
+	if len(r.children[id]) > 0 {
+		return domain.ErrRoleHasChildren
+	}
+	if len(r.members[id]) > 0 {
+		return domain.ErrRoleInUse
+	}
+	delete(r.roles, id)
+	delete(r.grants, id)
+	return nil
+}
+
+func (r *roleRepo) GetPermissionIDs(context.Context) ([]string, error) {
+	return r.permissions, nil
+}
+
+func (r *roleRepo) SetRolePermissions(_ context.Context, roleID int, ids []string) ([]string, []string, error) {
+	if _, ok := r.roles[roleID]; !ok {
+		return nil, nil, httputil.ErrNotFound
+	}
+	var added, removed []string
+	for _, id := range r.grants[roleID] {
+		if !slices.Contains(ids, id) {
+			removed = append(removed, id)
+		}
+	}
+	for _, id := range ids {
+		if !slices.Contains(r.grants[roleID], id) {
+			added = append(added, id)
+		}
+	}
+	r.grants[roleID] = slices.Clone(ids)
+	return added, removed, nil
+}
+
+func (r *roleRepo) RemovePermissionFromRole(_ context.Context, roleID int, permissionID string) error {
+	i := slices.Index(r.grants[roleID], permissionID)
+	if i < 0 {
+		return httputil.ErrNotFound
+	}
+	r.grants[roleID] = slices.Delete(r.grants[roleID], i, i+1)
+	return nil
+}
+
+func (r *roleRepo) UnassignRoleFromUser(_ context.Context, userID uuid.UUID, roleID int) error {
+	i := slices.Index(r.members[roleID], userID)
+	if i < 0 {
+		return httputil.ErrNotFound
+	}
+	r.members[roleID] = slices.Delete(r.members[roleID], i, i+1)
+	return nil
+}
+
+// eventRecorder stands in for the NATS connection and keeps the subjects the service publishes.
+type eventRecorder struct{ subjects []string }
+
+func (r *eventRecorder) PublishMsg(msg *nats.Msg) error {
+	r.subjects = append(r.subjects, msg.Subject)
+	return nil
+}
+
+func newRoleRepo() *roleRepo {
+	return &roleRepo{
+		roles: map[int]*domain.Role{
+			1: {ID: 1, Name: "Administrator"},
+			2: {ID: 2, Name: "Editor"},
+			3: {ID: 3, Name: "Reviewer"},
+		},
+		grants:      map[int][]string{2: {"cms.page.read", "cms.page.write"}},
+		members:     map[int][]uuid.UUID{1: {uuid.New()}},
+		children:    map[int][]int{2: {3}},
+		permissions: []string{"cms.page.read", "cms.page.write", "cms.page.publish"},
+	}
+}
+
+func TestDeleteRoleGuards(t *testing.T) {
+	repo := newRoleRepo()
+	published := &eventRecorder{}
+	svc := authService{repo: repo, nc: published, cache: newAccessCache(time.Minute)}
+	ctx := context.Background()
+
+	if err := svc.DeleteRole(ctx, 1); !errors.Is(err, domain.ErrRoleInUse) {
+		t.Fatalf("expected a role with members to be kept, got %v", err)
+	}
+	if err := svc.DeleteRole(ctx, 2); !errors.Is(err, domain.ErrRoleHasChildren) {
+		t.Fatalf("expected an inherited role to be kept, got %v", err)
+	}
+	if len(published.subjects) != 0 || len(repo.roles) != 3 {
+		t.Fatalf("expected refused deletes to change nothing, got events %v", published.subjects)
+	}
+
+	if err := svc.DeleteRole(ctx, 3); err != nil {
+		t.Fatalf("delete role: %v", err)
+	}
+	if _, ok := repo.roles[3]; ok || !slices.Equal(published.subjects, []string{events.AuthRoleDeleted}) {
+		t.Fatalf("expected the free role to be deleted, got events %v", published.subjects)
+	}
+}
+
+func TestSetRolePermissions(t *testing.T) {
+	repo := newRoleRepo()
+	published := &eventRecorder{}
+	svc := authService{repo: repo, nc: published, cache: newAccessCache(time.Minute)}
+	ctx := context.Background()
+
+	added, removed, err := svc.SetRolePermissions(ctx, 2, []string{"cms.page.read", " cms.page.publish ", "cms.page.publish", ""})
+	if err != nil {
+		t.Fatalf("set permissions: %v", err)
+	}
+	if !slices.Equal(added, []string{"cms.page.publish"}) || !slices.Equal(removed, []string{"cms.page.write"}) {
+		t.Fatalf("unexpected difference: added %v, removed %v", added, removed)
+	}
+	if !slices.Equal(published.subjects, []string{events.AuthCacheInvalidate, events.AuthRolePermsChanged}) || svc.cache.generation != 1 {
+		t.Fatalf("expected the change to clear the cache and be published, got events %v", published.subjects)
+	}
+
+	added, removed, err = svc.SetRolePermissions(ctx, 2, []string{"cms.page.publish", "cms.page.read"})
+	if err != nil {
+		t.Fatalf("set permissions: %v", err)
+	}
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatalf("expected no difference, got added %v, removed %v", added, removed)
+	}
+	if len(published.subjects) != 2 || svc.cache.generation != 1 {
+		t.Fatalf("expected an unchanged set to publish nothing, got events %v", published.subjects)
+	}
+
+	if _, _, err := svc.SetRolePermissions(ctx, 2, []string{"cms.page.delete"}); !errors.Is(err, domain.ErrUnknownPermission) {
+		t.Fatalf("expected an unknown permission to be rejected, got %v", err)
+	}
+	if !slices.Equal(repo.grants[2], []string{"cms.page.publish", "cms.page.read"}) {
+		t.Fatalf("expected a rejected set to leave the grants alone, got %v", repo.grants[2])
+	}
+}
+
+func TestRemoveRoleGrantsAndMembers(t *testing.T) {
+	repo := newRoleRepo()
+	published := &eventRecorder{}
+	svc := authService{repo: repo, nc: published, cache: newAccessCache(time.Minute)}
+	ctx := context.Background()
+
+	if err := svc.RemovePermissionFromRole(ctx, 2, "cms.page.publish"); !errors.Is(err, httputil.ErrNotFound) {
+		t.Fatalf("expected a missing grant to be reported, got %v", err)
+	}
+	if err := svc.UnassignRole(ctx, uuid.New(), 1); !errors.Is(err, httputil.ErrNotFound) {
+		t.Fatalf("expected a missing assignment to be reported, got %v", err)
+	}
+	if len(published.subjects) != 0 || svc.cache.generation != 0 {
+		t.Fatalf("expected nothing to be published, got events %v", published.subjects)
+	}
+
+	member := repo.members[1][0]
+	if err := svc.UnassignRole(ctx, member, 1); err != nil {
+		t.Fatalf("unassign role: %v", err)
+	}
+	if !slices.Equal(published.subjects, []string{events.AuthCacheInvalidate, events.AuthUserRoleUnassigned}) || svc.cache.generation != 1 {
+		t.Fatalf("expected the unassignment to clear the cache and be published, got events %v", published.subjects)
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (a authService) Refresh(ctx context.Context, refreshToken string, meta domain.SessionMeta) (*domain.TokenPair, error) {
+	current, err := a.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil, httputil.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	if current.UsedAt != nil {
+		return nil, a.revokeReusedFamily(ctx, current.SessionID)
+	}
+
+	session, err := a.repo.GetSession(ctx, current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, httputil.ErrUnauthorized
+	}
+
+	raw, next, err := a.newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.RotateRefreshToken(ctx, current.ID, next); err != nil {
+		if errors.Is(err, httputil.ErrConflict) {
+			// Another request rotated this token first: treat it exactly like a replay.
+			return nil, a.revokeReusedFamily(ctx, session.ID)
+		}
+		return nil, err
+	}
+
+	return a.issueTokenPair(ctx, session, raw)
+}
+
+func (a authService) Logout(ctx context.Context, refreshToken string) error {
+	token, err := a.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil // Logging out twice is not an error
+		}
+		return err
+	}
+	return a.repo.RevokeSession(ctx, token.SessionID)
+}
+
+func (a authService) ValidateAccessToken(ctx context.Context, token string) (*domain.UserClaims, error) {
+	// MFA challenge tokens are signed by the same keys but carry no session, so they fail the sid check below.
+	claims := &domain.UserClaims{}
+	parsed, err := a.cfg.Keys.Parse(token, claims)
+	if err != nil || !parsed.Valid {
+		return nil, httputil.ErrUnauthorized
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, httputil.ErrUnauthorized
+	}
+	active, err := a.repo.IsSessionActive(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, httputil.ErrUnauthorized
+	}
+
+	return claims, nil
+}
+
+func (a authService) JWKS() domain.JSONWebKeySet {
+	return a.cfg.Keys.JWKS()
+}
+
+func (a authService) ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	return a.repo.ListActiveSessions(ctx, userID)
+}
+
+func (a authService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return a.repo.RevokeUserSession(ctx, userID, sessionID)
+}
+
+// startSession opens a new session for the user and returns its first token pair.
+// amr lists the authentication methods the user went through to open it.
+func (a authService) startSession(ctx context.Context, userID uuid.UUID, meta domain.SessionMeta, amr []string) (*domain.TokenPair, error) {
+	session := &domain.Session{
+		ID:        uuid.New(),
+		UserID:    userID,
+		UserAgent: meta.UserAgent,
+		IPAddress: meta.IPAddress,
+		AMR:       amr,
+		ExpiresAt: time.Now().Add(a.cfg.RefreshTokenTTL),
+	}
+
+	raw, token, err := a.newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.CreateSession(ctx, session, token); err != nil {
+		return nil, err
+	}
+
+	a.record(audit.WithActor(ctx, userID, nil), audit.Entry{
+		Action:     domain.AuditLoginSucceeded,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   userID.String(),
+		Changes:    map[string]any{"session_id": session.ID, "amr": amr},
+	})
+	return a.issueTokenPair(ctx, session, raw)
+}
+
+func (a authService) revokeReusedFamily(ctx context.Context, sessionID uuid.UUID) error {
+	slog.Warn("refresh token reuse detected, revoking session", "session_id", sessionID)
+	if err := a.repo.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	return httputil.ErrUnauthorized
+}
+
+func (a authService) newRefreshToken(sessionID uuid.UUID) (string, *domain.RefreshToken, error) {
+	raw, hash, err := generateToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &domain.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.cfg.RefreshTokenTTL),
+	}, nil
+}
+
+// issueTokenPair signs an access token for the session. The role requirements are evaluated on every
+// issue, so a role that starts requiring two-factor authentication applies from the next refresh.
+func (a authService) issueTokenPair(ctx context.Context, session *domain.Session, refreshToken string) (*domain.TokenPair, error) {
+	pending, err := a.mfaPending(ctx, session.UserID, session.AMR)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ttl := a.cfg.AccessTokenTTL
+	var act *domain.Actor
+	if session.ImpersonatorID != nil {
+		// Impersonation sessions cannot be refreshed, so their only token lasts as long as the session.
+		ttl = session.ExpiresAt.Sub(now).Truncate(time.Second)
+		act = &domain.Actor{Subject: session.ImpersonatorID.String()}
+	}
+	claims := domain.UserClaims{
+		UserID:     session.UserID.String(),
+		SessionID:  session.ID.String(),
+		AMR:        session.AMR,
+		MFAPending: pending,
+		Act:        act,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   session.UserID.String(),
+		},
+	}
+
+	accessToken, err := a.cfg.Keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(ttl.Seconds()),
+		SessionID:    session.ID,
+		MFAPending:   pending,
+	}, nil
+}
+
+// generateToken returns a random URL-safe token together with the hash that should be persisted.
+func generateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// sessionRepo implements only the repository calls exercised by token issuing and validation.
+type sessionRepo struct {
+	domain.Repository
+	active    map[uuid.UUID]bool
+	userRoles map[uuid.UUID][]int
+	parents   map[int][]int // Roles each role inherits from
+	mfaRoles  map[int]bool  // Roles that require two-factor authentication
+}
+
+func (r sessionRepo) IsSessionActive(_ context.Context, id uuid.UUID) (bool, error) {
+	return r.active[id], nil
+}
+
+// UserRequiresMFA follows role inheritance like the effective_roles query of the Postgres repository.
+func (r sessionRepo) UserRequiresMFA(_ context.Context, userID uuid.UUID) (bool, error) {
+	seen := map[int]bool{}
+	pending := slices.Clone(r.userRoles[userID])
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if r.mfaRoles[id] {
+			return true, nil
+		}
+		pending = append(pending, r.parents[id]...)
+	}
+	return false, nil
+}
+
+func TestValidateAccessTokenChecksSession(t *testing.T) {
+	userID, liveID, revokedID := uuid.New(), uuid.New(), uuid.New()
+	svc := authService{
+		repo: sessionRepo{active: map[uuid.UUID]bool{liveID: true}},
+		cfg:  Config{Keys: newTestKeyRing(t), AccessTokenTTL: time.Minute},
+	}
+
+	live, err := svc.issueTokenPair(context.Background(), &domain.Session{ID: liveID, UserID: userID}, "")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	claims, err := svc.ValidateAccessToken(context.Background(), live.AccessToken)
+	if err != nil {
+		t.Fatalf("expected live session token to validate: %v", err)
+	}
+	if claims.UserID != userID.String() || claims.SessionID != liveID.String() {
+		t.Fatalf("unexpected claims: %#v", claims)
+	}
+
+	revoked, err := svc.issueTokenPair(context.Background(), &domain.Session{ID: revokedID, UserID: userID}, "")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	if _, err := svc.ValidateAccessToken(context.Background(), revoked.AccessToken); !errors.Is(err, httputil.ErrUnauthorized) {
+		t.Fatalf("expected revoked session to be rejected, got %v", err)
+	}
+
+	other := authService{repo: svc.repo, cfg: Config{Keys: newTestKeyRing(t), AccessTokenTTL: time.Minute}}
+	if _, err := other.ValidateAccessToken(context.Background(), live.AccessToken); !errors.Is(err, httputil.ErrUnauthorized) {
+		t.Fatalf("expected token signed with another key to be rejected, got %v", err)
+	}
+}
+
+func TestMFAChallengeIsNotAnAccessToken(t *testing.T) {
+	svc := authService{
+		repo: sessionRepo{},
+		cfg:  Config{Keys: newTestKeyRing(t), MFAChallengeTTL: time.Minute},
+	}
+
+	challenge, err := svc.issueMFAChallenge(uuid.New(), []string{domain.AMRPassword})
+	if err != nil {
+		t.Fatalf("issue challenge: %v", err)
+	}
+	if _, err := svc.ValidateAccessToken(context.Background(), challenge); !errors.Is(err, httputil.ErrUnauthorized) {
+		t.Fatalf("expected challenge token to be rejected as access token, got %v", err)
+	}
+}
+
+func TestIssueTokenPairFlagsMissingRequiredMFA(t *testing.T) {
+	sessionID := uuid.New()
+	admin, editor, reviewer := uuid.New(), uuid.New(), uuid.New()
+	svc := authService{
+		repo: sessionRepo{
+			active:    map[uuid.UUID]bool{sessionID: true},
+			userRoles: map[uuid.UUID][]int{admin: {1}, editor: {2}, reviewer: {3}},
+			parents:   map[int][]int{2: {1}},
+			mfaRoles:  map[int]bool{1: true},
+		},
+		cfg: Config{Keys: newTestKeyRing(t), AccessTokenTTL: time.Minute},
+	}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		amr    []string
+		want   bool
+	}{
+		{"password only", admin, []string{domain.AMRPassword}, true},
+		{"second factor", admin, []string{domain.AMRPassword, domain.AMRMFA}, false},
+		{"inherited role", editor, []string{domain.AMRPassword}, true},
+		{"no role requires it", reviewer, []string{domain.AMRPassword}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, err := svc.issueTokenPair(context.Background(), &domain.Session{ID: sessionID, UserID: tt.userID, AMR: tt.amr}, "")
+			if err != nil {
+				t.Fatalf("issue token: %v", err)
+			}
+			claims, err := svc.ValidateAccessToken(context.Background(), pair.AccessToken)
+			if err != nil {
+				t.Fatalf("validate token: %v", err)
+			}
+			if claims.MFAPending != tt.want || pair.MFAPending != tt.want {
+				t.Fatalf("expected mfa pending %v, got claim %v and pair %v", tt.want, claims.MFAPending, pair.MFAPending)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+)
+
+// ThrottlePolicy controls how failed logins counted for one scope are slowed down and locked out.
+type ThrottlePolicy struct {
+	FreeAttempts    int           // Failures tolerated before delays start
+	MaxFailures     int           // Failures that trigger a lockout; zero disables lockouts
+	BaseDelay       time.Duration // First delay, doubled on every further failure
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration // Also the window after which old failures are forgotten
+}
+
+// delay returns the wait imposed after the given number of consecutive failures.
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	over := failures - p.FreeAttempts
+	if over <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := 1; i < over && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay)
+}
+
+// retryAfter returns how long the client has to wait before its next attempt is evaluated,
+// and whether the wait comes from a lockout rather than a progressive delay.
+func (p ThrottlePolicy) retryAfter(t *domain.LoginThrottle, now time.Time) (time.Duration, bool) {
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return t.LockedUntil.Sub(now), true
+	}
+	// The attempt that reached the limit may still be in progress; it locks the counter if it fails.
+	if p.MaxFailures > 0 && t.Failures >= p.MaxFailures {
+		if until := t.LastFailureAt.Add(p.LockoutDuration); now.Before(until) {
+			return until.Sub(now), true
+		}
+	}
+	if d := p.delay(t.Failures); d > 0 {
+		if next := t.LastFailureAt.Add(d); now.Before(next) {
+			return next.Sub(now), false
+		}
+	}
+	return 0, false
+}
+
+type throttleTarget struct {
+	scope  string
+	key    string
+	policy ThrottlePolicy
+}
+
+// loginTargets lists the counters a login attempt is checked against: the account and, when known, the client IP.
+func (a authService) loginTargets(email string, meta domain.SessionMeta) []throttleTarget {
+	targets := []throttleTarget{{domain.ThrottleScopeAccount, normalizeEmail(email), a.cfg.AccountThrottle}}
+	if meta.IPAddress != "" {
+		targets = append(targets, throttleTarget{domain.ThrottleScopeIP, meta.IPAddress, a.cfg.IPThrottle})
+	}
+	return targets
+}
+
+// loginAttempt is an attempt counted against its throttle targets before the credentials were checked.
+// Every attempt ends with failed or succeeded; release takes back whatever neither settled.
+type loginAttempt struct {
+	svc     authService
+	targets []throttleTarget
+	counted []countedAttempt
+}
+
+type countedAttempt struct {
+	target     throttleTarget
+	throttle   *domain.LoginThrottle
+	previousAt time.Time
+}
+
+// admitLoginAttempt counts the attempt as a failure before any password hashing, refusing it while one
+// of its counters is delayed or locked. Each counter is checked and incremented in one locked step, so
+// concurrent attempts cannot all slip past the check before the first failure is recorded.
+func (a authService) admitLoginAttempt(ctx context.Context, targets []throttleTarget) (*loginAttempt, error) {
+	attempt := &loginAttempt{svc: a, targets: targets}
+	now := time.Now()
+	for _, t := range targets {
+		var previousAt time.Time
+		throttle, err := a.repo.CountLoginAttempt(ctx, t.scope, t.key, now.Add(-t.policy.LockoutDuration), func(current *domain.LoginThrottle) error {
+			previousAt = current.LastFailureAt
+			// Read the clock once the counter is locked, as concurrent attempts may have just been counted.
+			if wait, locked := t.policy.retryAfter(current, time.Now()); wait > 0 {
+				base := domain.ErrTooManyAttempts
+				if locked {
+					base = domain.ErrLoginLocked
+				}
+				return &domain.LoginThrottledError{Err: base, RetryAfter: wait}
+			}
+			return nil
+		})
+		if err != nil {
+			attempt.release(ctx)
+			return nil, err
+		}
+		attempt.counted = append(attempt.counted, countedAttempt{target: t, throttle: throttle, previousAt: previousAt})
+	}
+	return attempt, nil
+}
+
+// failed keeps the attempt counted and locks the counters that reached their limit.
+// Errors are logged rather than returned so the caller still answers with the original failure.
+func (l *loginAttempt) failed(ctx context.Context, userID *uuid.UUID) {
+	a := l.svc
+	entry := audit.Entry{Action: domain.AuditLoginFailed, TargetType: domain.AuditTargetUser, Changes: map[string]string{"email": l.targets[0].key}}
+	if userID != nil {
+		entry.TargetID = userID.String()
+	}
+	a.record(ctx, entry)
+
+	counted := l.counted
+	l.counted = nil
+	now := time.Now()
+	for _, c := range counted {
+		t := c.target
+		if t.policy.MaxFailures <= 0 || c.throttle.Failures < t.policy.MaxFailures {
+			continue
+		}
+
+		until := now.Add(t.policy.LockoutDuration)
+		locked, err := a.repo.LockLogin(ctx, t.scope, t.key, until)
+		if err != nil {
+			slog.Error("failed to lock login", "scope", t.scope, "error", err)
+			continue
+		}
+		if !locked {
+			continue
+		}
+
+		slog.Warn("login locked after repeated failures", "scope", t.scope, "key", t.key, "until", until)
+		data := events.AuthLoginLockedData{
+			Scope:       t.scope,
+			Key:         t.key,
+			Failures:    c.throttle.Failures,
+			LockedUntil: until,
+		}
+		if t.scope == domain.ThrottleScopeAccount {
+			data.UserID = userID
+		}
+		a.publish(ctx, events.AuthLoginLocked, data)
+	}
+}
+
+// succeeded resets the account counter. The IP counter only takes the attempt back and is otherwise
+// left to expire on its own, so one valid account cannot be used to keep guessing others from the same address.
+func (l *loginAttempt) succeeded(ctx context.Context) {
+	for i, c := range l.counted {
+		if c.target.scope != domain.ThrottleScopeAccount {
+			continue
+		}
+		if err := l.svc.repo.ClearLoginThrottle(ctx, c.target.scope, c.target.key); err != nil {
+			slog.Error("failed to clear login throttle", "error", err)
+		}
+		l.counted = slices.Delete(l.counted, i, i+1)
+		break
+	}
+	l.release(ctx)
+}
+
+// release takes back the counts of an attempt that neither failed nor succeeded, such as a correct
+// password still waiting for its second factor. It does nothing once the attempt is settled.
+func (l *loginAttempt) release(ctx context.Context) {
+	for _, c := range l.counted {
+		err := l.svc.repo.ReleaseLoginAttempt(ctx, c.target.scope, c.target.key, c.throttle.LastFailureAt, c.previousAt)
+		if err != nil {
+			slog.Error("failed to release login attempt", "scope", c.target.scope, "error", err)
+		}
+	}
+	l.counted = nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/password"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func TestThrottlePolicyDelay(t *testing.T) {
+	p := ThrottlePolicy{FreeAttempts: 3, MaxFailures: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	want := map[int]time.Duration{
+		0: 0,
+		3: 0,
+		4: time.Second,
+		5: 2 * time.Second,
+		6: 4 * time.Second,
+		7: 8 * time.Second,
+		8: 10 * time.Second,
+		9: 10 * time.Second,
+	}
+	for failures, d := range want {
+		if got := p.delay(failures); got != d {
+			t.Errorf("%d failures: expected %s, got %s", failures, d, got)
+		}
+	}
+}
+
+func TestThrottlePolicyRetryAfter(t *testing.T) {
+	p := ThrottlePolicy{FreeAttempts: 3, MaxFailures: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	now := time.Now()
+
+	delayed := &domain.LoginThrottle{Failures: 5, LastFailureAt: now.Add(-time.Second)}
+	if wait, locked := p.retryAfter(delayed, now); wait != time.Second || locked {
+		t.Fatalf("expected a one second delay, got %s (locked %v)", wait, locked)
+	}
+
+	elapsed := &domain.LoginThrottle{Failures: 5, LastFailureAt: now.Add(-3 * time.Second)}
+	if wait, _ := p.retryAfter(elapsed, now); wait != 0 {
+		t.Fatalf("expected the delay to have elapsed, got %s", wait)
+	}
+
+	until := now.Add(time.Minute)
+	lockedOut := &domain.LoginThrottle{Failures: 10, LastFailureAt: now, LockedUntil: &until}
+	if wait, locked := p.retryAfter(lockedOut, now); wait != time.Minute || !locked {
+		t.Fatalf("expected a one minute lockout, got %s (locked %v)", wait, locked)
+	}
+
+	expired := now.Add(-time.Minute)
+	lockedOut.LockedUntil = &expired
+	lockedOut.LastFailureAt = now.Add(-time.Hour)
+	if wait, _ := p.retryAfter(lockedOut, now); wait != 0 {
+		t.Fatalf("expected an expired lockout to allow attempts, got %s", wait)
+	}
+}
+
+// throttleRepo keeps login counters in memory. Its mutex stands in for the row lock taken by
+// CountLoginAttempt.
+type throttleRepo struct {
+	domain.Repository
+	user   *domain.User
+	factor *domain.UserMFA
+
+	mu        sync.Mutex
+	throttles map[string]*domain.LoginThrottle
+	locks     int
+}
+
+func (r *throttleRepo) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	if email != r.user.Email {
+		return nil, httputil.ErrNotFound
+	}
+	return r.user, nil
+}
+
+func (r *throttleRepo) GetUserByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	if id != r.user.ID {
+		return nil, httputil.ErrNotFound
+	}
+	return r.user, nil
+}
+
+func (r *throttleRepo) GetUserMFA(_ context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	if r.factor == nil || userID != r.user.ID {
+		return nil, httputil.ErrNotFound
+	}
+	return r.factor, nil
+}
+
+func (r *throttleRepo) UserRequiresMFA(context.Context, uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (r *throttleRepo) ConsumeRecoveryCode(context.Context, uuid.UUID, string) (bool, error) {
+	return false, nil
+}
+
+func (r *throttleRepo) CountLoginAttempt(_ context.Context, scope, key string, _ time.Time, admit func(*domain.LoginThrottle) error) (*domain.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.throttles[scope+":"+key]
+	if !ok {
+		t = &domain.LoginThrottle{Scope: scope, Key: key, LastFailureAt: time.Now()}
+		r.throttles[scope+":"+key] = t
+	}
+	current := *t
+	if err := admit(&current); err != nil {
+		return nil, err
+	}
+	t.Failures++
+	t.LastFailureAt = time.Now()
+	counted := *t
+	return &counted, nil
+}
+
+func (r *throttleRepo) ReleaseLoginAttempt(_ context.Context, scope, key string, _, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.throttles[scope+":"+key].Failures--
+	return nil
+}
+
+func (r *throttleRepo) LockLogin(_ context.Context, scope, key string, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.throttles[scope+":"+key]
+	if t.LockedUntil != nil {
+		return false, nil
+	}
+	t.LockedUntil = &until
+	r.locks++
+	return true, nil
+}
+
+func TestConcurrentLoginFailures(t *testing.T) {
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm:  password.AlgorithmBcrypt,
+		BcryptCost: 4,
+		Argon2id:   password.Argon2id{Memory: 64, Iterations: 1, Parallelism: 1},
+	})
+	if err != nil {
+		t.Fatalf("new hasher: %v", err)
+	}
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	repo := &throttleRepo{
+		user:      &domain.User{ID: uuid.New(), Email: "ana@example.com", PasswordHash: hash},
+		throttles: map[string]*domain.LoginThrottle{},
+	}
+	svc := authService{repo: repo, cfg: Config{
+		Hasher:          hasher,
+		AccountThrottle: ThrottlePolicy{FreeAttempts: 3, MaxFailures: 5, LockoutDuration: time.Minute},
+	}}
+
+	const attempts = 20
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Login(context.Background(), "ana@example.com", "wrong guess", domain.SessionMeta{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var guesses, refused int
+	for err := range errs {
+		switch {
+		case errors.Is(err, httputil.ErrUnauthorized):
+			guesses++
+		case errors.Is(err, domain.ErrLoginLocked):
+			refused++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if guesses != 5 || refused != attempts-5 {
+		t.Fatalf("expected 5 guesses before the lockout, got %d guesses and %d refusals", guesses, refused)
+	}
+	if repo.locks != 1 {
+		t.Fatalf("expected the account to be locked once, got %d", repo.locks)
+	}
+
+	if _, err := svc.Login(context.Background(), "ana@example.com", "correct horse", domain.SessionMeta{}); !errors.Is(err, domain.ErrLoginLocked) {
+		t.Fatalf("expected the right password to be refused while locked, got %v", err)
+	}
+}
+
+func TestDisableTOTPCountsFailedCodes(t *testing.T) {
+	confirmed := time.Now()
+	user := &domain.User{ID: uuid.New(), Email: "ana@example.com"}
+	repo := &throttleRepo{
+		user:      user,
+		factor:    &domain.UserMFA{UserID: user.ID, Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", ConfirmedAt: &confirmed},
+		throttles: map[string]*domain.LoginThrottle{},
+	}
+	svc := authService{repo: repo, cfg: Config{
+		AccountThrottle: ThrottlePolicy{MaxFailures: 3, LockoutDuration: time.Minute},
+	}}
+	ctx := context.Background()
+
+	for range 3 {
+		if err := svc.DisableTOTP(ctx, user.ID, "not-a-code"); !errors.Is(err, domain.ErrInvalidMFACode) {
+			t.Fatalf("expected a wrong code to be rejected, got %v", err)
+		}
+	}
+	if repo.locks != 1 {
+		t.Fatalf("expected the account to be locked once, got %d", repo.locks)
+	}
+	if err := svc.DisableTOTP(ctx, user.ID, "not-a-code"); !errors.Is(err, domain.ErrLoginLocked) {
+		t.Fatalf("expected further codes to be refused while locked, got %v", err)
+	}
+}
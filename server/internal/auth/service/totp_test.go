@@ -0,0 +1,68 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// Test vectors from RFC 6238, appendix B (SHA-1).
+	key := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1111111111: "14050471",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	}
+	for unix, want := range vectors {
+		if got := totpCode(key, unix/totpPeriod, 8); got != want {
+			t.Errorf("time %d: expected %s, got %s", unix, want, got)
+		}
+	}
+}
+
+func TestVerifyTOTPWindowAndReplay(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := totpEncoding.DecodeString(secret)
+	now := time.Unix(1_700_000_000, 0)
+	current := totpStep(now)
+
+	if step, ok := verifyTOTP(secret, totpCode(key, current-1, totpDigits), now, 0); !ok || step != current-1 {
+		t.Fatal("expected the previous step to be accepted")
+	}
+	if _, ok := verifyTOTP(secret, totpCode(key, current-2, totpDigits), now, 0); ok {
+		t.Fatal("expected a code two steps old to be rejected")
+	}
+	if _, ok := verifyTOTP(secret, totpCode(key, current, totpDigits), now, current); ok {
+		t.Fatal("expected an already used step to be rejected")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes(200)
+	if err != nil {
+		t.Fatalf("generate codes: %v", err)
+	}
+	format := regexp.MustCompile(`^[a-hjkmnp-z2-9]{4}-[a-hjkmnp-z2-9]{4}$`)
+	seen := map[string]bool{}
+	chars := map[rune]bool{}
+	for _, c := range codes {
+		if !format.MatchString(c) || seen[c] {
+			t.Fatalf("unexpected or repeated code %q", c)
+		}
+		seen[c] = true
+		for _, r := range strings.ReplaceAll(c, "-", "") {
+			chars[r] = true
+		}
+	}
+	// 1600 uniform draws leave a character of the alphabet unused with negligible probability.
+	if len(chars) != 31 {
+		t.Fatalf("expected every character of the alphabet to be used, got %d", len(chars))
+	}
+}
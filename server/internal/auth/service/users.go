@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (a authService) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, int, error) {
+	filter.Search = strings.TrimSpace(filter.Search)
+	return a.repo.ListUsers(ctx, filter)
+}
+
+func (a authService) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return a.repo.GetUserByID(ctx, id)
+}
+
+func (a authService) UpdateUser(ctx context.Context, id uuid.UUID, update domain.UserUpdate) (*domain.User, error) {
+	u, err := a.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := &httputil.ValidationError{}
+	if update.Email != nil {
+		u.Email = strings.TrimSpace(*update.Email)
+		if _, err := mail.ParseAddress(u.Email); err != nil {
+			invalid.Add("email", "must be a valid email address")
+		}
+	}
+	if update.FullName != nil {
+		u.FullName = strings.TrimSpace(*update.FullName)
+		if u.FullName == "" {
+			invalid.Add("full_name", "is required")
+		}
+	}
+	if err := invalid.OrNil(); err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.UpdateUserProfile(ctx, u); err != nil {
+		return nil, err
+	}
+
+	a.publish(ctx, events.AuthUserUpdated, events.AuthUserUpdatedData{UserID: u.ID, Email: u.Email, FullName: u.FullName})
+	return u, nil
+}
+
+// ArchiveUser soft-deletes the account and signs it out everywhere.
+func (a authService) ArchiveUser(ctx context.Context, actorID, id uuid.UUID) error {
+	if actorID == id {
+		return httputil.NewCodedError(httputil.ErrBadRequest, "CANNOT_ARCHIVE_SELF", "you cannot archive your own account")
+	}
+
+	u, err := a.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	archived, err := a.repo.ArchiveUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.RevokeUserSessions(ctx, id); err != nil {
+		return err
+	}
+
+	if archived {
+		a.publish(ctx, events.AuthUserDeleted, events.AuthUserDeletedData{UserID: u.ID, Email: u.Email})
+	}
+	return nil
+}
+
+func (a authService) RestoreUser(ctx context.Context, id uuid.UUID) error {
+	u, err := a.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	restored, err := a.repo.RestoreUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if restored {
+		a.publish(ctx, events.AuthUserUpdated, events.AuthUserUpdatedData{UserID: u.ID, Email: u.Email, FullName: u.FullName, Restored: true})
+	}
+	return nil
+}
+
+// UnlockUser clears the failed login counter of the account, lifting any delay or lockout.
+// Counters kept per client IP are not affected.
+func (a authService) UnlockUser(ctx context.Context, id uuid.UUID) error {
+	u, err := a.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.ClearLoginThrottle(ctx, domain.ThrottleScopeAccount, normalizeEmail(u.Email)); err != nil {
+		return err
+	}
+
+	a.publish(ctx, events.AuthUserUnlocked, events.AuthUserUnlockedData{UserID: u.ID, Email: u.Email})
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/auth/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/mail"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (a authService) ConfirmEmail(ctx context.Context, token string) error {
+	t, err := a.repo.ConsumeUserToken(ctx, domain.TokenPurposeEmailVerification, hashToken(token))
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	activated, err := a.repo.ActivateUser(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+	if activated {
+		a.publish(ctx, events.AuthUserActivated, events.AuthUserActivatedData{UserID: t.UserID})
+	}
+	return nil
+}
+
+// ResendVerificationEmail answers the same way, and as fast, for unknown, active and pending accounts
+// so it cannot be used to enumerate registered emails.
+func (a authService) ResendVerificationEmail(ctx context.Context, email string) error {
+	u, err := a.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, httputil.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if u.ActivatedAt != nil || u.ArchivedAt != nil {
+		return nil
+	}
+
+	a.inBackground(ctx, func(ctx context.Context) {
+		if err := a.sendVerificationEmail(ctx, u); err != nil {
+			slog.Error("failed to send verification email", "user_id", u.ID, "error", err)
+		}
+	})
+	return nil
+}
+
+// sendVerificationEmail replaces any pending verification link with a new one and mails it.
+func (a authService) sendVerificationEmail(ctx context.Context, u *domain.User) error {
+	if err := a.repo.InvalidateUserTokens(ctx, u.ID, domain.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	raw, err := a.issueUserToken(ctx, u.ID, domain.TokenPurposeEmailVerification, a.cfg.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.cfg.Mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nConfirm your email address to activate your account. The link expires in %s.\n\n%s/verify-email?token=%s\n",
+			u.FullName, a.cfg.VerificationTTL, a.cfg.AppURL, raw,
+		),
+	})
+}
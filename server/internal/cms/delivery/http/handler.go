@@ -2,12 +2,12 @@ package http
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/jsonutil"
 )
@@ -16,16 +16,24 @@ type CMSHandler struct {
 	svc domain.Service
 }
 
-func RegisterHTTPHandlers(r chi.Router, svc domain.Service) {
+func RegisterHTTPHandlers(r chi.Router, svc domain.Service, guard authz.Guard) {
 	h := &CMSHandler{svc: svc}
 
 	r.Route("/pages", func(r chi.Router) {
-		r.Post("/", h.CreateDraft)
+		r.With(guard.RequireAnyPermission(domain.PermissionPageWrite, domain.PermissionPageCreate)).Post("/", h.CreateDraft)
+
+		// Access to existing pages is decided per page by the service: the global permission,
+		// ownership or a page grant.
+		r.Get("/", h.List)
 		r.Get("/{slug}", h.GetBySlug)
 		r.Put("/{id}/metadata", h.UpdateMetadata)
 		r.Put("/{id}/layout", h.UpdateLayout)
 		r.Post("/{id}/publish", h.Publish)
 		r.Post("/{id}/archive", h.Archive)
+		r.Put("/{id}/owner", h.SetOwner)
+		r.Get("/{id}/grants", h.ListGrants)
+		r.Post("/{id}/grants", h.AddGrant)
+		r.Delete("/{id}/grants/{grantID}", h.RemoveGrant)
 	})
 }
 
@@ -37,7 +45,7 @@ func (h *CMSHandler) CreateDraft(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.CreateDraft(r.Context(), req.Title); err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
@@ -53,17 +61,23 @@ func (h *CMSHandler) GetBySlug(w http.ResponseWriter, r *http.Request) {
 
 	page, err := h.svc.GetPageBySlug(r.Context(), slug)
 	if err != nil {
-		if errors.Is(err, httputil.ErrNotFound) {
-			jsonutil.RenderError(w, http.StatusNotFound, "NOT_FOUND", "Page not found")
-			return
-		}
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
 	jsonutil.RenderJSON(w, http.StatusOK, page)
 }
 
+func (h *CMSHandler) List(w http.ResponseWriter, r *http.Request) {
+	pages, err := h.svc.ListPages(r.Context())
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, pages)
+}
+
 func (h *CMSHandler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -79,7 +93,7 @@ func (h *CMSHandler) UpdateMetadata(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.UpdatePageMetadata(r.Context(), id, req); err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
@@ -101,7 +115,7 @@ func (h *CMSHandler) UpdateLayout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.UpdatePageLayout(r.Context(), id, req); err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
@@ -117,7 +131,7 @@ func (h *CMSHandler) Publish(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.PublishPage(r.Context(), id); err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
@@ -133,9 +147,93 @@ func (h *CMSHandler) Archive(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.ArchivePage(r.Context(), id); err != nil {
-		jsonutil.RenderError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		renderError(w, err)
 		return
 	}
 
 	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"message": "Page archived successfully"})
 }
+
+func (h *CMSHandler) SetOwner(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid page ID")
+		return
+	}
+
+	var req domain.PageOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	if err := h.svc.SetPageOwner(r.Context(), id, req.OwnerID); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, map[string]string{"message": "Owner updated successfully"})
+}
+
+func (h *CMSHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid page ID")
+		return
+	}
+
+	grants, err := h.svc.ListPageGrants(r.Context(), id)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusOK, grants)
+}
+
+func (h *CMSHandler) AddGrant(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid page ID")
+		return
+	}
+
+	var req domain.PageGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to parse request body")
+		return
+	}
+
+	grant, err := h.svc.AddPageGrant(r.Context(), id, req)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	jsonutil.RenderJSON(w, http.StatusCreated, grant)
+}
+
+func (h *CMSHandler) RemoveGrant(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid page ID")
+		return
+	}
+	grantID, err := uuid.Parse(chi.URLParam(r, "grantID"))
+	if err != nil {
+		jsonutil.RenderError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid grant ID")
+		return
+	}
+
+	if err := h.svc.RemovePageGrant(r.Context(), id, grantID); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func renderError(w http.ResponseWriter, err error) {
+	status, code := httputil.MapError(err)
+	jsonutil.RenderError(w, status, code, err.Error())
+}
@@ -39,3 +39,15 @@ type BlockRequest struct {
 	Type    string                 `json:"type"`
 	Content map[string]interface{} `json:"content"`
 }
+
+// PageGrantRequest handles page grant creation. Exactly one of UserID and RoleID must be set.
+type PageGrantRequest struct {
+	UserID     *uuid.UUID `json:"user_id"`
+	RoleID     *int       `json:"role_id"`
+	Permission string     `json:"permission"`
+}
+
+// PageOwnerRequest handles ownership transfers. A null owner leaves the page without one.
+type PageOwnerRequest struct {
+	OwnerID *uuid.UUID `json:"owner_id"`
+}
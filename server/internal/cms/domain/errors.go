@@ -0,0 +1,5 @@
+package domain
+
+import "github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+
+var ErrInvalidPageGrant = httputil.NewCodedError(httputil.ErrBadRequest, "INVALID_PAGE_GRANT", "a page grant needs either a user or a role, and a page permission")
@@ -10,6 +10,7 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Page, error)
 	GetBySlug(ctx context.Context, slug string) (*Page, error)
 	List(ctx context.Context) ([]Page, error)
+	ListAccessible(ctx context.Context, userID uuid.UUID, roleIDs []int) ([]Page, error)
 	Create(ctx context.Context, page *Page) error
 	Update(ctx context.Context, page *Page) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -21,6 +22,12 @@ type Repository interface {
 
 	// SEO & Status
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+
+	// Page access
+	SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error
+	GetGrants(ctx context.Context, pageID uuid.UUID) ([]PageGrant, error)
+	AddGrant(ctx context.Context, grant *PageGrant) error
+	DeleteGrant(ctx context.Context, pageID, grantID uuid.UUID) error
 }
 
 type Service interface {
@@ -34,4 +41,11 @@ type Service interface {
 
 	// Public Facing
 	GetPageBySlug(ctx context.Context, Slug string) (*Page, error)
+	ListPages(ctx context.Context) ([]Page, error)
+
+	// Page access
+	SetPageOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error
+	ListPageGrants(ctx context.Context, id uuid.UUID) ([]PageGrant, error)
+	AddPageGrant(ctx context.Context, id uuid.UUID, req PageGrantRequest) (*PageGrant, error)
+	RemovePageGrant(ctx context.Context, id, grantID uuid.UUID) error
 }
@@ -1,11 +1,28 @@
 package domain
 
+import "github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+
 const (
 	PermissionPageRead   = "cms.page.read"
+	PermissionPageCreate = "cms.page.create"
 	PermissionPageWrite  = "cms.page.write"
 	PermissionPageDelete = "cms.page.delete"
 )
 
-func GetAvailablePermission() []string {
-	return []string{PermissionPageRead, PermissionPageWrite, PermissionPageDelete}
+// IsPagePermission reports whether permission can be granted on a single page.
+func IsPagePermission(permission string) bool {
+	switch permission {
+	case PermissionPageRead, PermissionPageWrite, PermissionPageDelete:
+		return true
+	}
+	return false
+}
+
+func GetAvailablePermission() []authz.PermissionDefinition {
+	return []authz.PermissionDefinition{
+		{ID: PermissionPageRead, Description: "View all pages and drafts", Group: "Pages", Order: 10},
+		{ID: PermissionPageCreate, Description: "Create pages, which the creator then owns", Group: "Pages", Order: 15},
+		{ID: PermissionPageWrite, Description: "Edit and publish all pages and manage page access", Group: "Pages", Order: 20},
+		{ID: PermissionPageDelete, Description: "Archive all pages", Group: "Pages", Order: 30},
+	}
 }
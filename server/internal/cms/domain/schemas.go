@@ -7,14 +7,15 @@ import (
 )
 
 type Page struct {
-	ID             uuid.UUID `json:"id"`
-	Title          string    `json:"title"`
-	Slug           string    `json:"slug"`
-	SEODescription string    `json:"seo_description"`
-	SEOKeywords    []string  `json:"seo_keywords"`
-	Status         string    `json:"status"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uuid.UUID  `json:"id"`
+	Title          string     `json:"title"`
+	Slug           string     `json:"slug"`
+	SEODescription string     `json:"seo_description"`
+	SEOKeywords    []string   `json:"seo_keywords"`
+	Status         string     `json:"status"`
+	OwnerID        *uuid.UUID `json:"owner_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 
 	Rows []Row `json:"rows,omitempty"`
 }
@@ -50,3 +51,13 @@ type Block struct {
 	IsHidden   bool           `json:"is_hidden"`
 	Content    map[string]any `json:"content"`
 }
+
+// PageGrant gives a user, or everyone holding a role, one permission on a single page.
+type PageGrant struct {
+	ID         uuid.UUID  `json:"id"`
+	PageID     uuid.UUID  `json:"page_id"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	RoleID     *int       `json:"role_id,omitempty"`
+	Permission string     `json:"permission"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/repositories"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/services"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 	menuDomain "github.com/rubenalves-dev/template-fullstack/server/internal/platform/menu"
 	globalEvents "github.com/rubenalves-dev/template-fullstack/server/pkg/events"
 )
@@ -20,9 +21,9 @@ type CmsModule struct {
 	Service domain.Service
 }
 
-func NewModule(pool *pgxpool.Pool, nc *nats.Conn) *CmsModule {
+func NewModule(pool *pgxpool.Pool, nc *nats.Conn, authorizer authz.Authorizer) *CmsModule {
 	repo := repositories.NewPgxRepository(pool)
-	svc := services.NewService(repo, nc)
+	svc := services.NewService(repo, nc, authorizer)
 
 	events.RegisterListeners(nc, svc)
 
@@ -58,6 +59,6 @@ func NewModule(pool *pgxpool.Pool, nc *nats.Conn) *CmsModule {
 	return &CmsModule{Service: svc}
 }
 
-func (m *CmsModule) RegisterRoutes(r chi.Router) {
-	http.RegisterHTTPHandlers(r, m.Service)
+func (m *CmsModule) RegisterRoutes(r chi.Router, guard authz.Guard) {
+	http.RegisterHTTPHandlers(r, m.Service, guard)
 }
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+func (p pxgRepo) SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error {
+	query := `UPDATE pages SET owner_id = $1, updated_at = NOW() WHERE id = $2`
+	tag, err := p.pool.Exec(ctx, query, ownerID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
+
+func (p pxgRepo) GetGrants(ctx context.Context, pageID uuid.UUID) ([]domain.PageGrant, error) {
+	query := `SELECT id, page_id, user_id, role_id, permission, created_at FROM page_grants WHERE page_id = $1 ORDER BY created_at`
+	rows, err := p.pool.Query(ctx, query, pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []domain.PageGrant{}
+	for rows.Next() {
+		var g domain.PageGrant
+		if err := rows.Scan(&g.ID, &g.PageID, &g.UserID, &g.RoleID, &g.Permission, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
+
+func (p pxgRepo) AddGrant(ctx context.Context, grant *domain.PageGrant) error {
+	query := `
+		INSERT INTO page_grants (id, page_id, user_id, role_id, permission)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err := p.pool.QueryRow(ctx, query, grant.ID, grant.PageID, grant.UserID, grant.RoleID, grant.Permission).Scan(&grant.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return httputil.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (p pxgRepo) DeleteGrant(ctx context.Context, pageID, grantID uuid.UUID) error {
+	tag, err := p.pool.Exec(ctx, `DELETE FROM page_grants WHERE id = $1 AND page_id = $2`, grantID, pageID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return httputil.ErrNotFound
+	}
+	return nil
+}
@@ -3,11 +3,13 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 )
 
 type pxgRepo struct {
@@ -18,52 +20,69 @@ func NewPgxRepository(pool *pgxpool.Pool) domain.Repository {
 	return &pxgRepo{pool: pool}
 }
 
-func (p pxgRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Page, error) {
-	query := `SELECT id, title, slug, seo_description, seo_keywords, status, created_at, updated_at FROM pages WHERE id = $1`
-	row := p.pool.QueryRow(ctx, query, id)
+const pageColumns = `id, title, slug, seo_description, seo_keywords, status, owner_id, created_at, updated_at`
 
+func scanPage(row pgx.Row) (*domain.Page, error) {
 	var page domain.Page
-	err := row.Scan(&page.ID, &page.Title, &page.Slug, &page.SEODescription, &page.SEOKeywords, &page.Status, &page.CreatedAt, &page.UpdatedAt)
+	err := row.Scan(&page.ID, &page.Title, &page.Slug, &page.SEODescription, &page.SEOKeywords, &page.Status, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, httputil.ErrNotFound
+		}
 		return nil, err
 	}
 	return &page, nil
 }
 
-func (p pxgRepo) GetBySlug(ctx context.Context, slug string) (*domain.Page, error) {
-	query := `SELECT id, title, slug, seo_description, seo_keywords, status, created_at, updated_at FROM pages WHERE slug = $1`
-	row := p.pool.QueryRow(ctx, query, slug)
+func (p pxgRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Page, error) {
+	query := `SELECT ` + pageColumns + ` FROM pages WHERE id = $1`
+	return scanPage(p.pool.QueryRow(ctx, query, id))
+}
 
-	var page domain.Page
-	err := row.Scan(&page.ID, &page.Title, &page.Slug, &page.SEODescription, &page.SEOKeywords, &page.Status, &page.CreatedAt, &page.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return &page, nil
+func (p pxgRepo) GetBySlug(ctx context.Context, slug string) (*domain.Page, error) {
+	query := `SELECT ` + pageColumns + ` FROM pages WHERE slug = $1`
+	return scanPage(p.pool.QueryRow(ctx, query, slug))
 }
 
 func (p pxgRepo) List(ctx context.Context) ([]domain.Page, error) {
-	query := `SELECT id, title, slug, seo_description, seo_keywords, status, created_at, updated_at FROM pages ORDER BY created_at DESC`
-	rows, err := p.pool.Query(ctx, query)
+	query := `SELECT ` + pageColumns + ` FROM pages ORDER BY created_at DESC`
+	return p.listPages(ctx, query)
+}
+
+// ListAccessible lists the pages the user owns or holds a grant on, directly or through one of roleIDs.
+func (p pxgRepo) ListAccessible(ctx context.Context, userID uuid.UUID, roleIDs []int) ([]domain.Page, error) {
+	query := `
+		SELECT ` + pageColumns + ` FROM pages
+		WHERE owner_id = $1
+			OR EXISTS (
+				SELECT 1 FROM page_grants g
+				WHERE g.page_id = pages.id AND (g.user_id = $1 OR g.role_id = ANY($2))
+			)
+		ORDER BY created_at DESC
+	`
+	return p.listPages(ctx, query, userID, roleIDs)
+}
+
+func (p pxgRepo) listPages(ctx context.Context, query string, args ...any) ([]domain.Page, error) {
+	rows, err := p.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var pages []domain.Page
+	pages := []domain.Page{}
 	for rows.Next() {
-		var page domain.Page
-		err := rows.Scan(&page.ID, &page.Title, &page.Slug, &page.SEODescription, &page.SEOKeywords, &page.Status, &page.CreatedAt, &page.UpdatedAt)
+		page, err := scanPage(rows)
 		if err != nil {
 			return nil, err
 		}
-		pages = append(pages, page)
+		pages = append(pages, *page)
 	}
 	return pages, nil
 }
 
 func (p pxgRepo) Create(ctx context.Context, page *domain.Page) error {
-	query := `INSERT INTO pages (id, title, slug, seo_description, seo_keywords, status) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := p.pool.Exec(ctx, query, page.ID, page.Title, page.Slug, page.SEODescription, page.SEOKeywords, page.Status)
+	query := `INSERT INTO pages (id, title, slug, seo_description, seo_keywords, status, owner_id) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := p.pool.Exec(ctx, query, page.ID, page.Title, page.Slug, page.SEODescription, page.SEOKeywords, page.Status, page.OwnerID)
 	return err
 }
 
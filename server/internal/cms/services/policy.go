@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
+)
+
+// canAccessPage decides whether subject may exercise permission on page. The global permission always
+// applies. Otherwise the owner may read and edit the page, and a grant to the caller or one of their roles
+// allows its own permission plus reading. API keys stay limited to their scopes either way.
+func canAccessPage(subject *authz.Subject, page *domain.Page, grants []domain.PageGrant, permission string) bool {
+	if subject.Can(permission) {
+		return true
+	}
+	if !subject.InScope(permission) {
+		return false
+	}
+
+	if page.OwnerID != nil && *page.OwnerID == subject.UserID &&
+		(permission == domain.PermissionPageRead || permission == domain.PermissionPageWrite) {
+		return true
+	}
+
+	for _, g := range grants {
+		holds := (g.UserID != nil && *g.UserID == subject.UserID) || (g.RoleID != nil && subject.HasRole(*g.RoleID))
+		if holds && (g.Permission == permission || permission == domain.PermissionPageRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizePage returns nil when the caller may exercise permission on page. Callers who cannot even
+// read the page get httputil.ErrNotFound so its existence is not disclosed.
+func (s service) authorizePage(ctx context.Context, page *domain.Page, permission string) error {
+	subject, err := s.authorizer.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	if subject.Can(permission) {
+		return nil
+	}
+
+	grants, err := s.repo.GetGrants(ctx, page.ID)
+	if err != nil {
+		return err
+	}
+	if canAccessPage(subject, page, grants, permission) {
+		return nil
+	}
+	if canAccessPage(subject, page, grants, domain.PermissionPageRead) {
+		return httputil.ErrForbidden
+	}
+	return httputil.ErrNotFound
+}
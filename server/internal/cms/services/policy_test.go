@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
+)
+
+func TestCanAccessPage(t *testing.T) {
+	owner, partner, stranger := uuid.New(), uuid.New(), uuid.New()
+	agencyRole := 7
+	page := &domain.Page{ID: uuid.New(), OwnerID: &owner}
+	grants := []domain.PageGrant{
+		{UserID: &partner, Permission: domain.PermissionPageWrite},
+		{RoleID: &agencyRole, Permission: domain.PermissionPageRead},
+	}
+
+	tests := []struct {
+		name       string
+		subject    *authz.Subject
+		permission string
+		want       bool
+	}{
+		{"global permission", &authz.Subject{UserID: stranger, Grants: authz.NewGrants([]string{"cms.*"})}, domain.PermissionPageDelete, true},
+		{"owner edits", &authz.Subject{UserID: owner}, domain.PermissionPageWrite, true},
+		{"owner cannot archive", &authz.Subject{UserID: owner}, domain.PermissionPageDelete, false},
+		{"user grant", &authz.Subject{UserID: partner}, domain.PermissionPageWrite, true},
+		{"user grant implies read", &authz.Subject{UserID: partner}, domain.PermissionPageRead, true},
+		{"user grant is not delete", &authz.Subject{UserID: partner}, domain.PermissionPageDelete, false},
+		{"role grant", &authz.Subject{UserID: stranger, RoleIDs: []int{agencyRole}}, domain.PermissionPageRead, true},
+		{"role grant is read only", &authz.Subject{UserID: stranger, RoleIDs: []int{agencyRole}}, domain.PermissionPageWrite, false},
+		{"no access", &authz.Subject{UserID: stranger}, domain.PermissionPageRead, false},
+		{"api key outside scope", &authz.Subject{UserID: owner, Scopes: authz.NewGrants([]string{domain.PermissionPageRead})}, domain.PermissionPageWrite, false},
+		{"api key within scope", &authz.Subject{UserID: owner, Scopes: authz.NewGrants([]string{"cms.page.*"})}, domain.PermissionPageWrite, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canAccessPage(tt.subject, page, grants, tt.permission); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -9,30 +9,42 @@ import (
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/cms/domain"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/audit"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 	"github.com/rubenalves-dev/template-fullstack/server/pkg/events"
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/httputil"
 )
 
 type service struct {
-	repo domain.Repository
-	nc   *nats.Conn
+	repo       domain.Repository
+	nc         *nats.Conn
+	authorizer authz.Authorizer
 }
 
-func NewService(repo domain.Repository, nc *nats.Conn) domain.Service {
+func NewService(repo domain.Repository, nc *nats.Conn, authorizer authz.Authorizer) domain.Service {
 	return &service{
-		repo: repo,
-		nc:   nc,
+		repo:       repo,
+		nc:         nc,
+		authorizer: authorizer,
 	}
 }
 
+// CreateDraft creates a page owned by the caller.
 func (s service) CreateDraft(ctx context.Context, title string) error {
+	subject, err := s.authorizer.Subject(ctx)
+	if err != nil {
+		return err
+	}
+
 	page := &domain.Page{
-		ID:     uuid.New(),
-		Title:  title,
-		Slug:   slugify(title),
-		Status: "draft",
+		ID:      uuid.New(),
+		Title:   title,
+		Slug:    slugify(title),
+		Status:  "draft",
+		OwnerID: &subject.UserID,
 	}
 
-	err := s.repo.Create(ctx, page)
+	err = s.repo.Create(ctx, page)
 	if err != nil {
 		return err
 	}
@@ -43,27 +55,31 @@ func (s service) CreateDraft(ctx context.Context, title string) error {
 		Slug:   page.Slug,
 	}
 	eventBytes, _ := json.Marshal(event)
-	return s.nc.Publish(events.CmsPageDrafted, eventBytes)
+	return s.nc.PublishMsg(audit.NewMsg(ctx, events.CmsPageDrafted, eventBytes))
 }
 
 func (s service) PublishPage(ctx context.Context, id uuid.UUID) error {
-	err := s.repo.UpdateStatus(ctx, id, "published")
+	page, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if err := s.authorizePage(ctx, page, domain.PermissionPageWrite); err != nil {
+		return err
+	}
 
-	page, err := s.repo.GetByID(ctx, id)
+	err = s.repo.UpdateStatus(ctx, id, "published")
 	if err != nil {
 		return err
 	}
 
 	event := events.CmsPagePublishedData{
-		PageID: page.ID,
-		Title:  page.Title,
-		Slug:   page.Slug,
+		PageID:         page.ID,
+		Title:          page.Title,
+		Slug:           page.Slug,
+		PreviousStatus: page.Status,
 	}
 	eventBytes, _ := json.Marshal(event)
-	return s.nc.Publish(events.CmsPagePublished, eventBytes)
+	return s.nc.PublishMsg(audit.NewMsg(ctx, events.CmsPagePublished, eventBytes))
 }
 
 func (s service) ArchivePage(ctx context.Context, id uuid.UUID) error {
@@ -71,18 +87,22 @@ func (s service) ArchivePage(ctx context.Context, id uuid.UUID) error {
 	if err != nil {
 		return err
 	}
+	if err := s.authorizePage(ctx, page, domain.PermissionPageDelete); err != nil {
+		return err
+	}
 	err = s.repo.UpdateStatus(ctx, page.ID, "archived")
 	if err != nil {
 		return err
 	}
 
 	event := events.CmsPageArchivedData{
-		PageID: page.ID,
-		Title:  page.Title,
-		Slug:   page.Slug,
+		PageID:         page.ID,
+		Title:          page.Title,
+		Slug:           page.Slug,
+		PreviousStatus: page.Status,
 	}
 	eventBytes, _ := json.Marshal(event)
-	return s.nc.Publish(events.CmsPageArchived, eventBytes)
+	return s.nc.PublishMsg(audit.NewMsg(ctx, events.CmsPageArchived, eventBytes))
 }
 
 func (s service) UpdatePageMetadata(ctx context.Context, id uuid.UUID, req domain.PageUpdateRequest) error {
@@ -90,7 +110,11 @@ func (s service) UpdatePageMetadata(ctx context.Context, id uuid.UUID, req domai
 	if err != nil {
 		return err
 	}
+	if err := s.authorizePage(ctx, page, domain.PermissionPageWrite); err != nil {
+		return err
+	}
 
+	before := pageMetadata(page)
 	if req.Title != nil {
 		page.Title = *req.Title
 	}
@@ -104,10 +128,37 @@ func (s service) UpdatePageMetadata(ctx context.Context, id uuid.UUID, req domai
 		page.SEOKeywords = req.Keywords
 	}
 
-	return s.repo.Update(ctx, page)
+	if err := s.repo.Update(ctx, page); err != nil {
+		return err
+	}
+
+	event := events.CmsPageMetadataUpdatedData{
+		PageID: page.ID,
+		Before: before,
+		After:  pageMetadata(page),
+	}
+	eventBytes, _ := json.Marshal(event)
+	return s.nc.PublishMsg(audit.NewMsg(ctx, events.CmsPageMetadataUpdated, eventBytes))
+}
+
+func pageMetadata(page *domain.Page) events.CmsPageMetadata {
+	return events.CmsPageMetadata{
+		Title:          page.Title,
+		Slug:           page.Slug,
+		SEODescription: page.SEODescription,
+		Keywords:       page.SEOKeywords,
+	}
 }
 
 func (s service) UpdatePageLayout(ctx context.Context, id uuid.UUID, layout []domain.RowRequest) error {
+	page, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizePage(ctx, page, domain.PermissionPageWrite); err != nil {
+		return err
+	}
+
 	domainRows := make([]domain.Row, len(layout))
 	for i, rowReq := range layout {
 		rowID := uuid.New()
@@ -152,7 +203,7 @@ func (s service) UpdatePageLayout(ctx context.Context, id uuid.UUID, layout []do
 		domainRows[i].Columns = domainCols
 	}
 
-	err := s.repo.SaveLayout(ctx, id, domainRows)
+	err = s.repo.SaveLayout(ctx, id, domainRows)
 	if err != nil {
 		return err
 	}
@@ -161,7 +212,7 @@ func (s service) UpdatePageLayout(ctx context.Context, id uuid.UUID, layout []do
 		PageID: id,
 	}
 	eventBytes, _ := json.Marshal(event)
-	return s.nc.Publish(events.CmsPageLayoutUpdated, eventBytes)
+	return s.nc.PublishMsg(audit.NewMsg(ctx, events.CmsPageLayoutUpdated, eventBytes))
 }
 
 func (s service) GetPageBySlug(ctx context.Context, Slug string) (*domain.Page, error) {
@@ -169,6 +220,9 @@ func (s service) GetPageBySlug(ctx context.Context, Slug string) (*domain.Page,
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorizePage(ctx, page, domain.PermissionPageRead); err != nil {
+		return nil, err
+	}
 
 	layout, err := s.repo.GetFullLayout(ctx, page.ID)
 	if err != nil {
@@ -179,6 +233,22 @@ func (s service) GetPageBySlug(ctx context.Context, Slug string) (*domain.Page,
 	return page, nil
 }
 
+// ListPages lists every page for callers with the global read permission, and otherwise the pages
+// they own or hold a grant on.
+func (s service) ListPages(ctx context.Context) ([]domain.Page, error) {
+	subject, err := s.authorizer.Subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subject.Can(domain.PermissionPageRead) {
+		return s.repo.List(ctx)
+	}
+	if !subject.InScope(domain.PermissionPageRead) {
+		return nil, httputil.ErrForbidden
+	}
+	return s.repo.ListAccessible(ctx, subject.UserID, subject.RoleIDs)
+}
+
 func slugify(text string) string {
 	var re = regexp.MustCompile("[^a-z0-9]+")
 	return strings.Trim(re.ReplaceAllString(strings.ToLower(text), "-"), "-")
@@ -0,0 +1,111 @@
+// Package audit carries who is behind a request from the HTTP layer to the audit log, either through
+// the context of direct service calls or through the headers of the NATS events they publish.
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+// NATS headers carrying Meta alongside event payloads.
+const (
+	HeaderActorID        = "Audit-Actor-Id"
+	HeaderImpersonatorID = "Audit-Impersonator-Id"
+	HeaderRequestID      = "Audit-Request-Id"
+	HeaderIPAddress      = "Audit-Ip-Address"
+)
+
+// Meta describes the request a change was made in. ActorID is nil for anonymous requests and for
+// changes made by the system itself, such as startup registrations.
+type Meta struct {
+	ActorID        *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	RequestID      string
+	IPAddress      string
+}
+
+// Entry is a change recorded directly by a service rather than derived from an event.
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    any
+}
+
+// Recorder stores audit entries. It is implemented by the audit module and handed to the modules
+// that record actions without publishing an event, such as logins.
+type Recorder interface {
+	Record(ctx context.Context, entry Entry)
+}
+
+type metaKey struct{}
+
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+func MetaFrom(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	return meta
+}
+
+// WithActor adds the authenticated caller to the request metadata.
+func WithActor(ctx context.Context, actorID uuid.UUID, impersonatorID *uuid.UUID) context.Context {
+	meta := MetaFrom(ctx)
+	meta.ActorID, meta.ImpersonatorID = &actorID, impersonatorID
+	return WithMeta(ctx, meta)
+}
+
+// Middleware records the request ID and client address. It must run after chi's RequestID and
+// httputil.RealIP middlewares; the authentication middleware adds the actor later on.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		ctx := WithMeta(r.Context(), Meta{RequestID: middleware.GetReqID(r.Context()), IPAddress: ip})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// NewMsg builds an event message carrying the request metadata of ctx in its headers.
+func NewMsg(ctx context.Context, subject string, data []byte) *nats.Msg {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+
+	meta := MetaFrom(ctx)
+	if meta.ActorID != nil {
+		msg.Header.Set(HeaderActorID, meta.ActorID.String())
+	}
+	if meta.ImpersonatorID != nil {
+		msg.Header.Set(HeaderImpersonatorID, meta.ImpersonatorID.String())
+	}
+	if meta.RequestID != "" {
+		msg.Header.Set(HeaderRequestID, meta.RequestID)
+	}
+	if meta.IPAddress != "" {
+		msg.Header.Set(HeaderIPAddress, meta.IPAddress)
+	}
+	return msg
+}
+
+// MetaFromMsg reads the metadata NewMsg attached. Events published without it yield an empty Meta.
+func MetaFromMsg(msg *nats.Msg) Meta {
+	meta := Meta{
+		RequestID: msg.Header.Get(HeaderRequestID),
+		IPAddress: msg.Header.Get(HeaderIPAddress),
+	}
+	if id, err := uuid.Parse(msg.Header.Get(HeaderActorID)); err == nil {
+		meta.ActorID = &id
+	}
+	if id, err := uuid.Parse(msg.Header.Get(HeaderImpersonatorID)); err == nil {
+		meta.ImpersonatorID = &id
+	}
+	return meta
+}
@@ -0,0 +1,12 @@
+package authz
+
+import "net/http"
+
+// Guard builds route middleware that checks the authenticated caller's permissions.
+// It is implemented by the auth module and handed to every module that registers protected routes.
+type Guard interface {
+	// RequirePermission allows the request only when the caller holds every listed permission.
+	RequirePermission(permissions ...string) func(next http.Handler) http.Handler
+	// RequireAnyPermission allows the request when the caller holds at least one listed permission.
+	RequireAnyPermission(permissions ...string) func(next http.Handler) http.Handler
+}
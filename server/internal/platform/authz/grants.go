@@ -0,0 +1,67 @@
+package authz
+
+import "strings"
+
+// Wildcard grants every permission. A grant ending in ".*" grants every permission under that prefix,
+// e.g. "cms.*" covers "cms.page.read" and "cms.page.*" covers "cms.page.write".
+const Wildcard = "*"
+
+// Grants is a set of granted permissions, possibly including wildcards.
+type Grants map[string]bool
+
+func NewGrants(permissions []string) Grants {
+	g := make(Grants, len(permissions))
+	for _, p := range permissions {
+		g[p] = true
+	}
+	return g
+}
+
+// Has reports whether permission is granted, exactly or through a wildcard. It also accepts a
+// wildcard as permission, which is granted when an equal or broader wildcard is.
+func (g Grants) Has(permission string) bool {
+	if g[permission] || g[Wildcard] {
+		return true
+	}
+	for i := 0; i < len(permission); i++ {
+		if permission[i] == '.' && g[permission[:i]+".*"] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWildcard reports whether grant is "*" or a prefix wildcard.
+func IsWildcard(grant string) bool {
+	return grant == Wildcard || strings.HasSuffix(grant, ".*")
+}
+
+// ValidGrant reports whether grant may be stored: an exact permission from registered, "*", or a
+// prefix wildcard that covers at least one registered permission.
+func ValidGrant(grant string, registered []string) bool {
+	if grant == Wildcard {
+		return true
+	}
+	if !IsWildcard(grant) {
+		if strings.Contains(grant, "*") {
+			return false
+		}
+		for _, p := range registered {
+			if p == grant {
+				return true
+			}
+		}
+		return false
+	}
+
+	prefix := strings.TrimSuffix(grant, "*")
+	if prefix == "." || strings.Contains(prefix, "*") || strings.Contains(prefix, "..") || strings.HasPrefix(prefix, ".") {
+		return false
+	}
+	for _, p := range registered {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package authz
+
+import "testing"
+
+func TestGrantsHas(t *testing.T) {
+	tests := []struct {
+		name       string
+		grants     []string
+		permission string
+		want       bool
+	}{
+		{"exact", []string{"cms.page.read"}, "cms.page.read", true},
+		{"missing", []string{"cms.page.read"}, "cms.page.write", false},
+		{"super admin", []string{"*"}, "auth.role.delete", true},
+		{"module wildcard", []string{"cms.*"}, "cms.page.write", true},
+		{"resource wildcard", []string{"cms.page.*"}, "cms.page.write", true},
+		{"other module", []string{"cms.*"}, "auth.role.read", false},
+		{"prefix is not a segment", []string{"cms.pa.*"}, "cms.page.read", false},
+		{"wildcard does not cover its module", []string{"cms.page.*"}, "cms.page", false},
+		{"narrower wildcard", []string{"cms.page.*"}, "cms.*", false},
+		{"broader wildcard", []string{"cms.*"}, "cms.page.*", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewGrants(tt.grants).Has(tt.permission); got != tt.want {
+				t.Fatalf("Has(%q) with %v = %v, want %v", tt.permission, tt.grants, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidGrant(t *testing.T) {
+	registered := []string{"cms.page.read", "cms.page.write", "auth.role.read"}
+
+	tests := []struct {
+		grant string
+		want  bool
+	}{
+		{"cms.page.read", true},
+		{"cms.page.delete", false},
+		{"*", true},
+		{"cms.*", true},
+		{"cms.page.*", true},
+		{"billing.*", false},
+		{"cm.*", false},
+		{".*", false},
+		{"cms.*.read", false},
+		{"cms.**", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.grant, func(t *testing.T) {
+			if got := ValidGrant(tt.grant, registered); got != tt.want {
+				t.Fatalf("ValidGrant(%q) = %v, want %v", tt.grant, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package authz
+
+// PermissionDefinition describes a permission a module registers. Group and Order only drive how the
+// backoffice lays out the permission catalogue; Order sorts permissions within their module.
+type PermissionDefinition struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Group       string `json:"group,omitempty"`
+	Order       int    `json:"order,omitempty"`
+}
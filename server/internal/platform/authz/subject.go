@@ -0,0 +1,42 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Subject is the authenticated caller as seen by modules that decide access per resource.
+type Subject struct {
+	UserID  uuid.UUID
+	RoleIDs []int  // Roles held directly or inherited
+	Grants  Grants // Effective permissions of the user
+	Scopes  Grants // Set for API keys, which may only act within these scopes
+}
+
+// Can reports whether the caller holds permission globally.
+func (s *Subject) Can(permission string) bool {
+	return s.Grants.Has(permission) && s.InScope(permission)
+}
+
+// InScope reports whether the credential of the request may exercise permission at all.
+// It is always true for user sessions.
+func (s *Subject) InScope(permission string) bool {
+	return s.Scopes == nil || s.Scopes.Has(permission)
+}
+
+// HasRole reports whether the caller holds the role, directly or through inheritance.
+func (s *Subject) HasRole(roleID int) bool {
+	for _, id := range s.RoleIDs {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer resolves the caller of a request. It is implemented by the auth module and handed to
+// modules that check access to individual resources rather than whole routes.
+type Authorizer interface {
+	Subject(ctx context.Context) (*Subject, error)
+}
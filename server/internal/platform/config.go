@@ -3,6 +3,7 @@ package platform
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -12,8 +13,94 @@ type Config struct {
 	Port         int    `env:"PORT" envDefault:"8080"`
 	Env          string `env:"ENV" envDefault:"development"`
 	DBConnString string `env:"DB_CONN_STRING,required"`
-	JWTSecret    string `env:"JWT_SECRET,required"`
 	NatsURL      string `env:"NATS_URL,required"`
+
+	// JWTKeysDir holds one PEM file per signing key, named <kid>.pem.
+	JWTKeysDir      string `env:"JWT_KEYS_DIR" envDefault:"keys"`
+	JWTSigningKeyID string `env:"JWT_SIGNING_KEY_ID"`
+
+	AccessTokenTTL   time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL  time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	PasswordResetTTL time.Duration `env:"PASSWORD_RESET_TTL" envDefault:"1h"`
+	VerificationTTL  time.Duration `env:"EMAIL_VERIFICATION_TTL" envDefault:"48h"`
+	MFAChallengeTTL  time.Duration `env:"MFA_CHALLENGE_TTL" envDefault:"5m"`
+	InvitationTTL    time.Duration `env:"INVITATION_TTL" envDefault:"168h"`
+
+	// AllowRegistration keeps /auth/register open to anyone. Disable it to onboard staff by invitation only.
+	AllowRegistration bool `env:"ALLOW_REGISTRATION" envDefault:"true"`
+
+	// MFAIssuer is the account issuer shown by authenticator apps.
+	MFAIssuer string `env:"MFA_ISSUER" envDefault:"Template Fullstack"`
+
+	// CORSAllowedOrigins lists the origins allowed to call the API. Browsers never send cookies to "*",
+	// so browser sessions need the backoffice origin listed explicitly.
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" envDefault:"*"`
+
+	// Browser sessions keep the tokens in HttpOnly cookies instead of returning them to the backoffice.
+	// SessionCookieSameSite is one of strict, lax or none; none requires SessionCookieSecure.
+	SessionCookies        bool   `env:"SESSION_COOKIES" envDefault:"false"`
+	SessionCookieDomain   string `env:"SESSION_COOKIE_DOMAIN"`
+	SessionCookieSecure   bool   `env:"SESSION_COOKIE_SECURE" envDefault:"true"`
+	SessionCookieSameSite string `env:"SESSION_COOKIE_SAMESITE" envDefault:"strict"`
+
+	// TrustedProxies lists the reverse proxies, as CIDR ranges or IPs, whose X-Forwarded-For and X-Real-IP
+	// headers give the client address. Requests from anywhere else are identified by their socket address.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	// AppURL is the public URL of the backoffice, used to build links sent by email.
+	AppURL string `env:"APP_URL" envDefault:"http://localhost:4200"`
+
+	// Failed login throttling. Delays start after the free attempts and double up to LoginMaxDelay;
+	// reaching the max failures locks the account or IP for LoginLockoutDuration.
+	LoginAccountFreeAttempts int           `env:"LOGIN_ACCOUNT_FREE_ATTEMPTS" envDefault:"3"`
+	LoginAccountMaxFailures  int           `env:"LOGIN_ACCOUNT_MAX_FAILURES" envDefault:"10"`
+	LoginIPFreeAttempts      int           `env:"LOGIN_IP_FREE_ATTEMPTS" envDefault:"20"`
+	LoginIPMaxFailures       int           `env:"LOGIN_IP_MAX_FAILURES" envDefault:"100"`
+	LoginBaseDelay           time.Duration `env:"LOGIN_BASE_DELAY" envDefault:"1s"`
+	LoginMaxDelay            time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"30s"`
+	LoginLockoutDuration     time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+
+	// Password policy for registration, reset and change. PasswordMaxBytes cannot exceed bcrypt's 72 bytes.
+	PasswordMinLength     int  `env:"PASSWORD_MIN_LENGTH" envDefault:"10"`
+	PasswordMaxBytes      int  `env:"PASSWORD_MAX_BYTES" envDefault:"72"`
+	PasswordCheckBreached bool `env:"PASSWORD_CHECK_BREACHED" envDefault:"true"`
+
+	// Password hashing. New hashes use PasswordHashAlgorithm (argon2id or bcrypt); hashes made with the
+	// other algorithm or other parameters are replaced at the next successful login.
+	PasswordHashAlgorithm     string `env:"PASSWORD_HASH_ALGORITHM" envDefault:"argon2id"`
+	PasswordBcryptCost        int    `env:"PASSWORD_BCRYPT_COST" envDefault:"12"`
+	PasswordArgon2Memory      uint32 `env:"PASSWORD_ARGON2_MEMORY" envDefault:"65536"` // KiB
+	PasswordArgon2Iterations  uint32 `env:"PASSWORD_ARGON2_ITERATIONS" envDefault:"3"`
+	PasswordArgon2Parallelism uint8  `env:"PASSWORD_ARGON2_PARALLELISM" envDefault:"4"`
+
+	APIKeyDefaultTTL time.Duration `env:"API_KEY_DEFAULT_TTL" envDefault:"2160h"`
+	APIKeyMaxTTL     time.Duration `env:"API_KEY_MAX_TTL" envDefault:"8760h"`
+
+	// AccessCacheTTL bounds how long cached permissions and menus live when an invalidation
+	// message is missed. Zero disables the cache.
+	AccessCacheTTL time.Duration `env:"ACCESS_CACHE_TTL" envDefault:"5m"`
+
+	// ImpersonationTTL is how long support staff may act as another user before starting again.
+	ImpersonationTTL time.Duration `env:"IMPERSONATION_TTL" envDefault:"15m"`
+
+	// Single sign-on through an OpenID provider; an empty OIDCIssuerURL disables it. OIDCRedirectURL is
+	// the backoffice page the provider sends users back to, which posts the code to /auth/oidc/callback.
+	// OIDCGroupRoles maps provider groups to local role names, as "group:Role;other-group:Other Role".
+	OIDCIssuerURL    string            `env:"OIDC_ISSUER_URL"`
+	OIDCClientID     string            `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string            `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string            `env:"OIDC_REDIRECT_URL" envDefault:"http://localhost:4200/auth/callback"`
+	OIDCScopes       []string          `env:"OIDC_SCOPES" envDefault:"openid,email,profile"`
+	OIDCGroupsClaim  string            `env:"OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	OIDCGroupRoles   map[string]string `env:"OIDC_GROUP_ROLES" envSeparator:";"`
+	OIDCStateTTL     time.Duration     `env:"OIDC_STATE_TTL" envDefault:"10m"`
+
+	MailTransport    string `env:"MAIL_TRANSPORT" envDefault:"log"`
+	MailFrom         string `env:"MAIL_FROM" envDefault:"no-reply@localhost"`
+	MailFileDir      string `env:"MAIL_FILE_DIR" envDefault:"tmp/mail"`
+	MailSMTPAddr     string `env:"MAIL_SMTP_ADDR"`
+	MailSMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+	MailSMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
 }
 
 func Load() (*Config, error) {
@@ -0,0 +1,113 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails. Modules depend on this interface so the transport can be swapped per environment.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the transport returned by NewSender.
+type Config struct {
+	Transport    string // log, file or smtp
+	From         string
+	FileDir      string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+func NewSender(cfg Config) (Sender, error) {
+	switch cfg.Transport {
+	case "", "log":
+		return LogSender{}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.FileDir, 0o755); err != nil {
+			return nil, fmt.Errorf("mail: create %s: %w", cfg.FileDir, err)
+		}
+		return FileSender{Dir: cfg.FileDir, From: cfg.From}, nil
+	case "smtp":
+		return SMTPSender{Addr: cfg.SMTPAddr, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("mail: unknown transport %q", cfg.Transport)
+	}
+}
+
+// LogSender writes emails to the application log. Intended for local development only.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	slog.Info("email sent", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// FileSender writes every email as an .eml file into Dir, so links can be opened from a mail client.
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+func (s FileSender) Send(_ context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	path := filepath.Join(s.Dir, name)
+	if err := os.WriteFile(path, render(s.From, msg), 0o600); err != nil {
+		return fmt.Errorf("mail: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// SMTPSender delivers emails through an SMTP relay using PLAIN authentication when credentials are set.
+type SMTPSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTPSender) Send(_ context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host := s.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if err := smtp.SendMail(s.Addr, auth, s.From, []string{msg.To}, render(s.From, msg)); err != nil {
+		return fmt.Errorf("mail: smtp send: %w", err)
+	}
+	return nil
+}
+
+// headerSanitizer drops line breaks so user supplied values cannot inject extra headers.
+var headerSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerSanitizer.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerSanitizer.Replace(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerSanitizer.Replace(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
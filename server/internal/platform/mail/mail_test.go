@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSenderWritesEml(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := NewSender(Config{Transport: "file", FileDir: dir, From: "no-reply@example.com"})
+	if err != nil {
+		t.Fatalf("new sender: %v", err)
+	}
+
+	err = sender.Send(context.Background(), Message{
+		To:      "user@example.com\r\nBcc: attacker@example.com",
+		Subject: "Reset your password",
+		Body:    "hello",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %d", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "Subject: Reset your password\r\n") || !strings.HasSuffix(content, "\r\n\r\nhello") {
+		t.Fatalf("unexpected email:\n%s", content)
+	}
+	if strings.Contains(content, "\r\nBcc:") {
+		t.Fatalf("header injection was not stripped:\n%s", content)
+	}
+}
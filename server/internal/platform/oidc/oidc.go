@@ -0,0 +1,325 @@
+// Package oidc is a minimal OpenID Connect relying party for the authorization code flow with PKCE.
+// It discovers the provider, builds authorization URLs and exchanges codes for verified ID token claims.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultScopes are requested when Config.Scopes is empty.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Config identifies this application to the provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string // Sent with HTTP Basic authentication; empty for public clients
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string // ID token claim listing the user's groups; defaults to "groups"
+
+	HTTPClient *http.Client
+}
+
+// Claims are the verified ID token claims the application relies on.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	AMR           []string
+}
+
+// Provider talks to one OpenID provider. Discovery and signing keys are fetched on first use and
+// cached, so the application starts even while the provider is unreachable.
+type Provider struct {
+	cfg Config
+
+	mu        sync.Mutex
+	metadata  *metadata
+	keys      map[string]any
+	keysAt    time.Time
+	keysAfter time.Duration
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// ErrExchange is returned when the provider rejects the code or returns an ID token that fails verification.
+var ErrExchange = errors.New("oidc: code exchange failed")
+
+func NewProvider(cfg Config) (*Provider, error) {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("oidc: issuer URL, client ID and redirect URL are required")
+	}
+	cfg.IssuerURL = strings.TrimSuffix(cfg.IssuerURL, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, keysAfter: time.Minute}, nil
+}
+
+// Issuer returns the configured issuer URL, which identities are linked under.
+func (p *Provider) Issuer() string {
+	return p.cfg.IssuerURL
+}
+
+// AuthCodeURL returns the URL the user is sent to. state and nonce must be unguessable and kept by
+// the caller, together with the verifier challenge was derived from.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the claims of the verified ID token.
+// nonce is the value sent with the authorization request.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &token)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || token.IDToken == "" {
+		return nil, fmt.Errorf("%w: token endpoint returned %d %s %s", ErrExchange, status, token.Error, token.ErrorDescription)
+	}
+
+	return p.verify(ctx, meta, token.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, meta *metadata, rawToken, nonce string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(rawToken, mapClaims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil || !parsed.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	// With several audiences the token must name this client as the authorized party.
+	if aud, _ := mapClaims.GetAudience(); len(aud) > 1 {
+		if azp, _ := mapClaims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: authorized party %q is not this client", ErrExchange, azp)
+		}
+	}
+	if got, _ := mapClaims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrExchange)
+	}
+
+	claims := &Claims{Issuer: meta.Issuer}
+	claims.Subject, _ = mapClaims.GetSubject()
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrExchange)
+	}
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	// Some providers send email_verified as a string.
+	switch v := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	claims.Groups = stringList(mapClaims[p.cfg.GroupsClaim])
+	claims.AMR = stringList(mapClaims["amr"])
+	return claims, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+	var meta metadata
+	status, err := p.doJSON(req, &meta)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery returned %d", status)
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", meta.Issuer, p.cfg.IssuerURL)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// key returns the signing key named kid. An unknown kid refetches the key set, at most once a
+// minute, so keys rotated by the provider are picked up.
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysAt) < p.keysAfter {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jsonWebKeySet
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("jwks returned %d", status)
+	}
+	p.keys, p.keysAt = set.publicKeys(), time.Now()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	// Providers with a single key may omit kid from their tokens.
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (p *Provider) doJSON(req *http.Request, dst any) (int, error) {
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oidc: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("oidc: read %s: %w", req.URL, err)
+	}
+	if err := json.Unmarshal(body, dst); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("oidc: decode %s: %w", req.URL, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// RandomString returns a URL-safe random value for states, nonces and PKCE verifiers.
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// S256Challenge derives the PKCE code challenge sent with the authorization request from verifier.
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
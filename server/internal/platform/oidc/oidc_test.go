@@ -0,0 +1,113 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc"
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/oidc/oidctest"
+)
+
+func newProvider(t *testing.T, srv *oidctest.Server) *oidc.Provider {
+	t.Helper()
+	p, err := oidc.NewProvider(oidc.Config{
+		IssuerURL:    srv.URL,
+		ClientID:     srv.ClientID,
+		ClientSecret: srv.ClientSecret,
+		RedirectURL:  "http://app.test/auth/callback",
+	})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	return p
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	srv := oidctest.NewServer("backoffice", "s3cret")
+	defer srv.Close()
+	p := newProvider(t, srv)
+	ctx := context.Background()
+
+	verifier, _ := oidc.RandomString()
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.S256Challenge(verifier))
+	if err != nil {
+		t.Fatalf("auth url: %v", err)
+	}
+	code, state, err := srv.Login(authURL, map[string]any{
+		"sub":            "ana",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"groups":         []string{"staff", "editors"},
+	})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("expected state to round-trip, got %q", state)
+	}
+
+	claims, err := p.Exchange(ctx, code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if claims.Subject != "ana" || claims.Email != "ana@example.com" || !claims.EmailVerified || claims.Issuer != srv.URL {
+		t.Fatalf("unexpected claims: %#v", claims)
+	}
+	if !slices.Equal(claims.Groups, []string{"staff", "editors"}) {
+		t.Fatalf("unexpected groups: %v", claims.Groups)
+	}
+
+	if _, err := p.Exchange(ctx, code, verifier, "nonce-1"); !errors.Is(err, oidc.ErrExchange) {
+		t.Fatalf("expected a used code to be rejected, got %v", err)
+	}
+}
+
+func TestExchangeRejections(t *testing.T) {
+	srv := oidctest.NewServer("backoffice", "s3cret")
+	defer srv.Close()
+	p := newProvider(t, srv)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		verifier string
+		nonce    string
+	}{
+		{"wrong PKCE verifier", "another-verifier", "nonce-1"},
+		{"nonce mismatch", "", "nonce-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, _ := oidc.RandomString()
+			authURL, err := p.AuthCodeURL(ctx, "state", "nonce-1", oidc.S256Challenge(verifier))
+			if err != nil {
+				t.Fatalf("auth url: %v", err)
+			}
+			code, _, err := srv.Login(authURL, nil)
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if _, err := p.Exchange(ctx, code, verifier, tt.nonce); !errors.Is(err, oidc.ErrExchange) {
+				t.Fatalf("expected exchange to fail, got %v", err)
+			}
+		})
+	}
+
+	other := oidctest.NewServer("backoffice", "s3cret")
+	defer other.Close()
+	verifier, _ := oidc.RandomString()
+	authURL, _ := newProvider(t, other).AuthCodeURL(ctx, "state", "nonce", oidc.S256Challenge(verifier))
+	code, _, err := other.Login(authURL, nil)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	// Codes from another provider are unknown to this one.
+	if _, err := p.Exchange(ctx, code, verifier, "nonce"); !errors.Is(err, oidc.ErrExchange) {
+		t.Fatalf("expected foreign code to be rejected, got %v", err)
+	}
+}
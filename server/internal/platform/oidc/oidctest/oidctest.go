@@ -0,0 +1,183 @@
+// Package oidctest provides an in-process OpenID provider for tests, built on httptest.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// Server issues ID tokens for whatever claims a test logs in with. It serves discovery, the key set
+// and a token endpoint that enforces PKCE, the redirect URI and client authentication.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	key   ed25519.PrivateKey
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+type grant struct {
+	claims      jwt.MapClaims
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+// NewServer starts a provider accepting the given client. Close it when the test ends.
+func NewServer(clientID, clientSecret string) *Server {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+
+	s := &Server{ClientID: clientID, ClientSecret: clientSecret, key: key, codes: make(map[string]grant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Login plays the user signing in at the authorization URL built by the client. It checks the
+// request and returns the code and state the provider would redirect back with. claims are added to
+// the ID token; sub defaults to "user".
+func (s *Server) Login(authURL string, claims map[string]any) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme+"://"+u.Host+u.Path != s.URL+"/authorize" {
+		return "", "", fmt.Errorf("oidctest: unexpected authorization endpoint %s", u.Path)
+	}
+	q := u.Query()
+	switch {
+	case q.Get("response_type") != "code":
+		return "", "", fmt.Errorf("oidctest: unsupported response_type %q", q.Get("response_type"))
+	case q.Get("client_id") != s.ClientID:
+		return "", "", fmt.Errorf("oidctest: unknown client %q", q.Get("client_id"))
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", "", fmt.Errorf("oidctest: PKCE with S256 is required")
+	}
+
+	idClaims := jwt.MapClaims{"sub": "user"}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	code = hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	s.codes[code] = grant{claims: idClaims, challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), redirectURI: q.Get("redirect_uri")}
+	s.mu.Unlock()
+	return code, q.Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := s.key.Public().(ed25519.PublicKey)
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kid": keyID,
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"use": "sig",
+		"alg": "EdDSA",
+		"x":   base64.RawURLEncoding.EncodeToString(pub),
+	}}})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id = r.PostForm.Get("client_id")
+	} else {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	}
+	if id != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	g, found := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError(w, "unsupported_grant_type")
+		return
+	case !found, r.PostForm.Get("redirect_uri") != g.redirectURI,
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   s.URL,
+		"aud":   s.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce,
+	}
+	for k, v := range g.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-" + signed[len(signed)-8:],
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,29 @@
+package password
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/bloom"
+)
+
+//go:generate go run gen_breached.go
+
+//go:embed breached.bloom
+var breachedData []byte
+
+var breachedFilter = sync.OnceValue(func() *bloom.Filter {
+	f := &bloom.Filter{}
+	if err := f.UnmarshalBinary(breachedData); err != nil {
+		panic("password: embedded breached.bloom is corrupt: " + err.Error())
+	}
+	return f
+})
+
+// IsBreached reports whether the password is on the bundled list of commonly breached passwords.
+// The check is case-insensitive. Being a Bloom filter it can flag roughly one unrelated password
+// in ten thousand, but never misses a listed one.
+func IsBreached(password string) bool {
+	return breachedFilter().Test([]byte(strings.ToLower(password)))
+}
@@ -0,0 +1,63 @@
+//go:build ignore
+
+// gen_breached builds breached.bloom from a plain-text password list.
+//
+//	go run gen_breached.go [-in breached_passwords.txt] [-out breached.bloom]
+package main
+
+import (
+	"bufio"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/rubenalves-dev/template-fullstack/server/pkg/bloom"
+)
+
+// suffixes are appended to every entry because they are how most people "strengthen" a common word.
+var suffixes = []string{
+	"", "1", "12", "123", "1234", "12345", "123456", "!", "1!", "123!", "01", "69", "007", "00",
+	"2019", "2020", "2021", "2022", "2023", "2024", "2025", "2026",
+}
+
+func main() {
+	in := flag.String("in", "breached_passwords.txt", "password list, one per line")
+	out := flag.String("out", "breached.bloom", "output filter")
+	rate := flag.Float64("rate", 0.0001, "false positive rate")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	seen := make(map[string]struct{})
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.ToLower(strings.TrimSpace(sc.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, s := range suffixes {
+			seen[line+s] = struct{}{}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	filter := bloom.New(len(seen), *rate)
+	for p := range seen {
+		filter.Add([]byte(p))
+	}
+	data, err := filter.MarshalBinary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s: %d passwords, %d bytes", *out, len(seen), len(data))
+}
@@ -0,0 +1,177 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported hashing algorithms.
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrUnknownHash is returned for stored hashes no configured algorithm recognises.
+var ErrUnknownHash = errors.New("password: unrecognised hash format")
+
+// Algorithm hashes passwords in a self-describing format, so a stored hash names the algorithm and
+// parameters it was made with.
+type Algorithm interface {
+	Hash(password string) (string, error)
+	// Recognizes reports whether encoded was produced by this algorithm.
+	Recognizes(encoded string) bool
+	// Verify checks password against a hash this algorithm recognises. outdated is true when the hash
+	// was made with other parameters than the current ones.
+	Verify(password, encoded string) (match, outdated bool, err error)
+}
+
+// HasherConfig selects the algorithm new hashes use and its parameters.
+type HasherConfig struct {
+	Algorithm  string // AlgorithmArgon2id or AlgorithmBcrypt
+	BcryptCost int
+	Argon2id   Argon2id
+}
+
+// Hasher hashes new passwords with its preferred algorithm and verifies hashes made by any
+// supported one, telling callers when a stored hash should be replaced.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+// NewHasher returns a hasher preferring cfg.Algorithm. Hashes of the other algorithm are still
+// verified, and reported as needing a rehash.
+func NewHasher(cfg HasherConfig) (*Hasher, error) {
+	argon := cfg.Argon2id
+	bc := Bcrypt{Cost: cfg.BcryptCost}
+	if bc.Cost < bcrypt.MinCost || bc.Cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("password: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if argon.Memory == 0 || argon.Iterations == 0 || argon.Parallelism == 0 {
+		return nil, errors.New("password: argon2id memory, iterations and parallelism must be positive")
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		return &Hasher{preferred: argon, algorithms: []Algorithm{argon, bc}}, nil
+	case AlgorithmBcrypt:
+		return &Hasher{preferred: bc, algorithms: []Algorithm{bc, argon}}, nil
+	default:
+		return nil, fmt.Errorf("password: unknown hash algorithm %q, want %s or %s", cfg.Algorithm, AlgorithmArgon2id, AlgorithmBcrypt)
+	}
+}
+
+// Hash hashes password with the preferred algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify checks password against encoded. needsRehash is only set on a match, when encoded was made
+// by another algorithm than the preferred one or with outdated parameters.
+func (h *Hasher) Verify(password, encoded string) (match, needsRehash bool, err error) {
+	for _, alg := range h.algorithms {
+		if !alg.Recognizes(encoded) {
+			continue
+		}
+		match, outdated, err := alg.Verify(password, encoded)
+		if err != nil || !match {
+			return false, false, err
+		}
+		return true, outdated || alg != h.preferred, nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+// Bcrypt hashes with bcrypt at the given cost, as "$2a$<cost>$...".
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(hash), err
+}
+
+func (b Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b Bcrypt) Verify(password, encoded string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, err
+	}
+	return true, cost != b.Cost, nil
+}
+
+// Argon2id hashes with argon2id in the PHC string format,
+// "$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>".
+type Argon2id struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a Argon2id) Verify(password, encoded string) (bool, bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, false, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrUnknownHash
+	}
+	var stored Argon2id
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &stored.Memory, &stored.Iterations, &stored.Parallelism)
+	if err != nil || stored.Memory == 0 || stored.Iterations == 0 || stored.Parallelism == 0 {
+		return false, false, ErrUnknownHash
+	}
+	salt, errSalt := base64.RawStdEncoding.DecodeString(parts[4])
+	key, errKey := base64.RawStdEncoding.DecodeString(parts[5])
+	if errSalt != nil || errKey != nil || len(key) == 0 {
+		return false, false, ErrUnknownHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, stored.Iterations, stored.Memory, stored.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+	outdated := stored != a || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+	return true, outdated, nil
+}
@@ -0,0 +1,69 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestHasher(t *testing.T, algorithm string, argon Argon2id, bcryptCost int) *Hasher {
+	t.Helper()
+	h, err := NewHasher(HasherConfig{Algorithm: algorithm, BcryptCost: bcryptCost, Argon2id: argon})
+	if err != nil {
+		t.Fatalf("new hasher: %v", err)
+	}
+	return h
+}
+
+func TestHasherUpgrades(t *testing.T) {
+	argon := Argon2id{Memory: 64, Iterations: 1, Parallelism: 1}
+	current := newTestHasher(t, AlgorithmArgon2id, argon, 4)
+
+	legacyBcrypt, _ := newTestHasher(t, AlgorithmBcrypt, argon, 4).Hash("violet-anchor")
+	weakerArgon, _ := newTestHasher(t, AlgorithmArgon2id, Argon2id{Memory: 32, Iterations: 1, Parallelism: 1}, 4).Hash("violet-anchor")
+	fresh, err := current.Hash("violet-anchor")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(fresh, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected encoding %q", fresh)
+	}
+
+	tests := []struct {
+		name        string
+		encoded     string
+		password    string
+		match       bool
+		needsRehash bool
+	}{
+		{"current hash", fresh, "violet-anchor", true, false},
+		{"wrong password", fresh, "violet-anchors", false, false},
+		{"other algorithm", legacyBcrypt, "violet-anchor", true, true},
+		{"other algorithm, wrong password", legacyBcrypt, "tundra", false, false},
+		{"outdated parameters", weakerArgon, "violet-anchor", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := current.Verify(tt.password, tt.encoded)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if match != tt.match || needsRehash != tt.needsRehash {
+				t.Fatalf("expected match=%v needsRehash=%v, got %v %v", tt.match, tt.needsRehash, match, needsRehash)
+			}
+		})
+	}
+
+	t.Run("bcrypt cost change", func(t *testing.T) {
+		stronger := newTestHasher(t, AlgorithmBcrypt, argon, 5)
+		if _, needsRehash, _ := stronger.Verify("violet-anchor", legacyBcrypt); !needsRehash {
+			t.Fatal("expected a lower bcrypt cost to need a rehash")
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, _, err := current.Verify("violet-anchor", "5f4dcc3b5aa765d61d8327deb882cf99"); !errors.Is(err, ErrUnknownHash) {
+			t.Fatalf("expected ErrUnknownHash, got %v", err)
+		}
+	})
+}
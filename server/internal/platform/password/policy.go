@@ -0,0 +1,73 @@
+// Package password holds the rules new passwords are checked against and how they are hashed.
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// BcryptMaxBytes is the longest input bcrypt hashes; anything after it is silently ignored.
+const BcryptMaxBytes = 72
+
+// minFragmentLength is the shortest part of an email or name worth rejecting,
+// so short names do not rule out unrelated passwords.
+const minFragmentLength = 4
+
+// Policy describes the rules a new password must satisfy.
+type Policy struct {
+	MinLength     int  // Minimum number of characters
+	MaxBytes      int  // Maximum encoded length, never above BcryptMaxBytes
+	CheckBreached bool // Reject passwords on the bundled breached list
+}
+
+// Validate returns the reasons the password is rejected, or nil when it is acceptable.
+// email and fullName belong to the account and must not appear in the password.
+func (p Policy) Validate(password, email, fullName string) []string {
+	var problems []string
+
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	maxBytes := p.MaxBytes
+	if maxBytes <= 0 || maxBytes > BcryptMaxBytes {
+		maxBytes = BcryptMaxBytes
+	}
+	if len(password) > maxBytes {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes long", maxBytes))
+	}
+
+	lower := strings.ToLower(password)
+	if containsAny(lower, emailFragments(email)) {
+		problems = append(problems, "must not contain your email address")
+	}
+	if containsAny(lower, nameFragments(fullName)) {
+		problems = append(problems, "must not contain your name")
+	}
+
+	if p.CheckBreached && password != "" && IsBreached(password) {
+		problems = append(problems, "is too common and appears in known data breaches")
+	}
+
+	return problems
+}
+
+func emailFragments(email string) []string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, _, _ := strings.Cut(email, "@")
+	return []string{email, local}
+}
+
+func nameFragments(fullName string) []string {
+	fields := strings.Fields(strings.ToLower(fullName))
+	return append(fields, strings.Join(fields, ""))
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, f := range fragments {
+		if utf8.RuneCountInString(f) >= minFragmentLength && strings.Contains(s, f) {
+			return true
+		}
+	}
+	return false
+}
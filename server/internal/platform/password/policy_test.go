@@ -0,0 +1,37 @@
+package password
+
+import "testing"
+
+func TestPolicyValidate(t *testing.T) {
+	p := Policy{MinLength: 10, MaxBytes: 72, CheckBreached: true}
+
+	tests := []struct {
+		name     string
+		password string
+		problems int
+	}{
+		{"acceptable", "violet-anchor-tundra", 0},
+		{"too short", "x7#kq", 1},
+		{"too long", string(make([]byte, 73)) + "violet-anchor", 1},
+		{"contains email and name", "marta.silva-2024!", 2},
+		{"contains name", "SilvaHouseBlue", 1},
+		{"breached", "Password123", 1},
+		{"breached with suffix", "sunshine2024", 1},
+		{"empty", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Validate(tt.password, "marta.silva@example.com", "Marta Silva")
+			if len(got) != tt.problems {
+				t.Fatalf("expected %d problems, got %v", tt.problems, got)
+			}
+		})
+	}
+}
+
+func TestPolicyCapsMaxBytesAtBcryptLimit(t *testing.T) {
+	p := Policy{MinLength: 1, MaxBytes: 200}
+	if got := p.Validate(string(make([]byte, 100)), "", ""); len(got) != 1 {
+		t.Fatalf("expected passwords over %d bytes to be rejected, got %v", BcryptMaxBytes, got)
+	}
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- New accounts stay inactive until their email is verified. Existing accounts keep their activation date.
+ALTER TABLE users ALTER COLUMN activated_at DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ALTER COLUMN activated_at SET DEFAULT NOW();
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One TOTP factor per user. confirmed_at stays NULL until the user proves the authenticator works;
+-- last_used_step records the last accepted 30 second window so a code cannot be replayed.
+CREATE TABLE user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Recovery codes are single use and only their hash is stored.
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    used_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (user_id, code_hash)
+);
+
+ALTER TABLE roles ADD COLUMN require_mfa BOOLEAN NOT NULL DEFAULT false;
+
+-- Authentication methods used to open the session (RFC 8176 values such as 'pwd' and 'mfa').
+ALTER TABLE sessions ADD COLUMN amr TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN amr;
+ALTER TABLE roles DROP COLUMN require_mfa;
+DROP TABLE mfa_recovery_codes;
+DROP TABLE user_mfa;
+-- +goose StatementEnd
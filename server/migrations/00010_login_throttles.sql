@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed login counters shared by every API replica. scope is 'account' (key: normalised email)
+-- or 'ip' (key: client address). Failures older than the lockout window are forgotten on the next failure.
+CREATE TABLE login_throttles (
+    scope VARCHAR(16) NOT NULL,
+    key VARCHAR(320) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (scope, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_throttles;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Display metadata for the permission catalogue. Modules send it along with their permission IDs.
+ALTER TABLE permissions
+    ADD COLUMN group_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE permissions
+    DROP COLUMN sort_order,
+    DROP COLUMN group_name;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set when a module's latest declaration no longer includes the permission, cleared when it is declared again.
+-- Deprecated permissions keep their role grants until an administrator purges them.
+ALTER TABLE permissions ADD COLUMN deprecated_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE permissions DROP COLUMN deprecated_at;
+-- +goose StatementEnd
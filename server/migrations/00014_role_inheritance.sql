@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A role inherits every permission of its parents, transitively. Writes reject cycles.
+CREATE TABLE role_parents (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    parent_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, parent_id),
+    CHECK (role_id <> parent_id)
+);
+
+CREATE INDEX idx_role_parents_parent_id ON role_parents(parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE role_parents;
+-- +goose StatementEnd
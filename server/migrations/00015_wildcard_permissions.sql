@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- role_permissions may now hold wildcard grants ("*", "cms.*", "cms.page.*") that match no single
+-- permission row, so grants are validated by the application instead of a foreign key.
+ALTER TABLE role_permissions DROP CONSTRAINT role_permissions_permission_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id NOT IN (SELECT id FROM permissions);
+ALTER TABLE role_permissions
+    ADD CONSTRAINT role_permissions_permission_id_fkey
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pages record who created them; owners may read and edit their pages without the global cms.page.* permissions.
+-- owner_id has no foreign key because users belong to the auth module.
+ALTER TABLE pages ADD COLUMN owner_id UUID;
+CREATE INDEX idx_pages_owner_id ON pages(owner_id);
+
+-- Per-page grants to a single user or to everyone holding a role.
+CREATE TABLE page_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    page_id UUID NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    user_id UUID,
+    role_id INTEGER,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK ((user_id IS NULL) <> (role_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_page_grants_user ON page_grants(page_id, user_id, permission) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_page_grants_role ON page_grants(page_id, role_id, permission) WHERE role_id IS NOT NULL;
+CREATE INDEX idx_page_grants_user_id ON page_grants(user_id);
+CREATE INDEX idx_page_grants_role_id ON page_grants(role_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE page_grants;
+ALTER TABLE pages DROP COLUMN owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Impersonation sessions belong to the impersonated user and record the staff member acting as them.
+-- They have no refresh tokens and expire after IMPERSONATION_TTL.
+ALTER TABLE sessions ADD COLUMN impersonator_id UUID REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX idx_sessions_impersonator_id ON sessions(impersonator_id) WHERE impersonator_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_sessions_impersonator_id;
+ALTER TABLE sessions DROP COLUMN impersonator_id;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Append-only record of security and content changes. Actors and targets are not foreign keys so
+-- entries outlive the users, roles and pages they mention.
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    actor_id UUID,
+    impersonator_id UUID,
+    action VARCHAR(150) NOT NULL,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id VARCHAR(255) NOT NULL DEFAULT '',
+    changes JSONB,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id, occurred_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, occurred_at DESC);
+CREATE INDEX idx_audit_log_action ON audit_log(action text_pattern_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pending single sign-on logins. The state is sent through the browser, so only its SHA-256 hash is
+-- stored; the PKCE verifier and nonce stay on the server. Rows are deleted when used or expired.
+CREATE TABLE oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Accounts at the OpenID provider linked to local users, matched on issuer and subject.
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (issuer, subject),
+    UNIQUE (user_id, issuer)
+);
+
+CREATE INDEX idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_identities;
+DROP TABLE oidc_login_states;
+-- +goose StatementEnd
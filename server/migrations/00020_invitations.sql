@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Invitations mailed to new staff members. Only the SHA-256 hash of the token is stored. An invitation
+-- is pending until it is accepted, revoked or expires; accepting it records the account it created.
+CREATE TABLE user_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    full_name VARCHAR(255) NOT NULL DEFAULT '',
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Roles granted when the invitation is accepted. Deleting a role drops it from pending invitations.
+CREATE TABLE user_invitation_roles (
+    invitation_id UUID NOT NULL REFERENCES user_invitations(id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (invitation_id, role_id)
+);
+
+CREATE INDEX idx_user_invitations_email ON user_invitations(lower(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_invitation_roles;
+DROP TABLE user_invitations;
+-- +goose StatementEnd
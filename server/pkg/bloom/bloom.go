@@ -0,0 +1,91 @@
+// Package bloom implements a fixed-size Bloom filter with a stable binary encoding,
+// so filters can be generated once and embedded in the binary.
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var magic = [4]byte{'B', 'L', 'M', '1'}
+
+// ErrInvalidEncoding is returned when decoding data that was not produced by MarshalBinary.
+var ErrInvalidEncoding = errors.New("bloom: invalid encoding")
+
+// Filter answers "definitely not present" or "probably present" for a set of byte strings.
+type Filter struct {
+	m    uint64 // number of bits
+	k    uint32 // number of hash functions
+	bits []uint64
+}
+
+// New sizes a filter for n items at the given false positive rate.
+func New(n int, falsePositiveRate float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	m = (m + 63) / 64 * 64
+	return &Filter{m: m, k: k, bits: make([]uint64, m/64)}
+}
+
+// Add inserts item into the filter.
+func (f *Filter) Add(item []byte) {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Test reports whether item may be in the filter. False means it was never added.
+func (f *Filter) Test(item []byte) bool {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the filter as magic, k, m and the little-endian bit words.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 16+len(f.bits)*8)
+	buf = append(buf, magic[:]...)
+	buf = binary.LittleEndian.AppendUint32(buf, f.k)
+	buf = binary.LittleEndian.AppendUint64(buf, f.m)
+	for _, w := range f.bits {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 || [4]byte(data[:4]) != magic {
+		return ErrInvalidEncoding
+	}
+	k := binary.LittleEndian.Uint32(data[4:8])
+	m := binary.LittleEndian.Uint64(data[8:16])
+	words := data[16:]
+	if k == 0 || m == 0 || m%64 != 0 || uint64(len(words)) != m/8 {
+		return ErrInvalidEncoding
+	}
+
+	f.k, f.m = k, m
+	f.bits = make([]uint64, m/64)
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(words[i*8:])
+	}
+	return nil
+}
+
+// hashes derives the two base hashes used for double hashing (Kirsch–Mitzenmacher).
+func hashes(item []byte) (uint64, uint64) {
+	sum := sha256.Sum256(item)
+	return binary.LittleEndian.Uint64(sum[0:8]), binary.LittleEndian.Uint64(sum[8:16]) | 1
+}
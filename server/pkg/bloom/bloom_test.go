@@ -0,0 +1,45 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilterMembershipAndRoundTrip(t *testing.T) {
+	f := New(1000, 0.001)
+	for i := range 1000 {
+		f.Add([]byte(fmt.Sprintf("item-%d", i)))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Filter
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	for i := range 1000 {
+		if !decoded.Test([]byte(fmt.Sprintf("item-%d", i))) {
+			t.Fatalf("item-%d missing after round trip", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := range 10000 {
+		if decoded.Test([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 50 {
+		t.Fatalf("expected about 10 false positives in 10000, got %d", falsePositives)
+	}
+}
+
+func TestUnmarshalRejectsGarbage(t *testing.T) {
+	var f Filter
+	if err := f.UnmarshalBinary([]byte("not a filter at all")); err != ErrInvalidEncoding {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+}
@@ -1,9 +1,203 @@
 package events
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 const (
-	AuthUserRegistered      = "auth.user.registered"
-	AuthUserUpdated         = "auth.user.updated"
-	AuthUserDeleted         = "auth.user.deleted"
-	AuthUserPasswordChanged = "auth.user.password.changed"
-	AuthUserPasswordReset   = "auth.user.password.reset"
+	AuthUserRegistered       = "auth.user.registered"
+	AuthUserActivated        = "auth.user.activated"
+	AuthUserUpdated          = "auth.user.updated"
+	AuthUserDeleted          = "auth.user.deleted"
+	AuthUserPasswordChanged  = "auth.user.password.changed"
+	AuthUserPasswordReset    = "auth.user.password.reset"
+	AuthUserMFAEnabled       = "auth.user.mfa.enabled"
+	AuthUserMFADisabled      = "auth.user.mfa.disabled"
+	AuthUserUnlocked         = "auth.user.unlocked"
+	AuthLoginLocked          = "auth.login.locked"
+	AuthAPIKeyCreated        = "auth.apikey.created"
+	AuthAPIKeyRevoked        = "auth.apikey.revoked"
+	AuthRoleCreated          = "auth.role.created"
+	AuthRoleUpdated          = "auth.role.updated"
+	AuthRoleDeleted          = "auth.role.deleted"
+	AuthRolePermsChanged     = "auth.role.permissions.changed"
+	AuthRoleParentsChanged   = "auth.role.parents.changed"
+	AuthUserRoleAssigned     = "auth.user.role.assigned"
+	AuthUserRoleUnassigned   = "auth.user.role.unassigned"
+	AuthPermsDeprecated      = "auth.permissions.deprecated"
+	AuthPermsPurged          = "auth.permissions.purged"
+	AuthCacheInvalidate      = "auth.cache.invalidate"
+	AuthImpersonationStarted = "auth.impersonation.started"
+	AuthImpersonationStopped = "auth.impersonation.stopped"
+	AuthUserIdentityLinked   = "auth.user.identity.linked"
+	AuthInvitationCreated    = "auth.invitation.created"
+	AuthInvitationRevoked    = "auth.invitation.revoked"
+	AuthInvitationAccepted   = "auth.invitation.accepted"
 )
+
+type AuthUserRegisteredData struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Email    string    `json:"email"`
+	FullName string    `json:"full_name"`
+}
+
+type AuthUserActivatedData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthUserUpdatedData struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Email    string    `json:"email"`
+	FullName string    `json:"full_name"`
+	Restored bool      `json:"restored,omitempty"`
+}
+
+type AuthUserDeletedData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+}
+
+type AuthUserPasswordChangedData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthUserPasswordResetData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthUserMFAEnabledData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthUserMFADisabledData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthUserUnlockedData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+}
+
+// AuthLoginLockedData describes a lockout after repeated failed logins. Scope is "account" (Key is
+// the email, UserID is set when it belongs to a user) or "ip" (Key is the client address).
+type AuthLoginLockedData struct {
+	Scope       string     `json:"scope"`
+	Key         string     `json:"key"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	Failures    int        `json:"failures"`
+	LockedUntil time.Time  `json:"locked_until"`
+}
+
+type AuthAPIKeyCreatedData struct {
+	KeyID     uuid.UUID `json:"key_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AuthAPIKeyRevokedData struct {
+	KeyID  uuid.UUID `json:"key_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type AuthRoleCreatedData struct {
+	RoleID int    `json:"role_id"`
+	Name   string `json:"name"`
+}
+
+type AuthRoleUpdatedData struct {
+	RoleID     int    `json:"role_id"`
+	Name       string `json:"name"`
+	RequireMFA bool   `json:"require_mfa"`
+}
+
+type AuthRoleDeletedData struct {
+	RoleID int    `json:"role_id"`
+	Name   string `json:"name"`
+}
+
+// AuthRolePermsChangedData lists the permissions granted to and taken from a role by a single change.
+type AuthRolePermsChangedData struct {
+	RoleID  int      `json:"role_id"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+type AuthRoleParentsChangedData struct {
+	RoleID    int   `json:"role_id"`
+	ParentIDs []int `json:"parent_ids"`
+}
+
+type AuthUserRoleAssignedData struct {
+	UserID uuid.UUID `json:"user_id"`
+	RoleID int       `json:"role_id"`
+}
+
+type AuthUserRoleUnassignedData struct {
+	UserID uuid.UUID `json:"user_id"`
+	RoleID int       `json:"role_id"`
+}
+
+// AuthPermsDeprecatedData lists the permissions a module stopped declaring.
+type AuthPermsDeprecatedData struct {
+	Module      string   `json:"module"`
+	Permissions []string `json:"permissions"`
+}
+
+type AuthPermsPurgedData struct {
+	Permissions []string `json:"permissions"`
+}
+
+// AuthCacheInvalidateData tells every API replica which cached permissions and menus to drop.
+type AuthCacheInvalidateData struct {
+	UserIDs  []uuid.UUID `json:"user_ids,omitempty"`
+	AllUsers bool        `json:"all_users,omitempty"`
+	Menus    bool        `json:"menus,omitempty"`
+}
+
+// AuthImpersonationStartedData describes an impersonation session: ActorID acts as UserID until ExpiresAt.
+type AuthImpersonationStartedData struct {
+	SessionID uuid.UUID `json:"session_id"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AuthImpersonationStoppedData is published when the actor ends the session. Sessions that simply
+// expire publish nothing; their end is the expires_at of the start event.
+type AuthImpersonationStoppedData struct {
+	SessionID uuid.UUID `json:"session_id"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// AuthUserIdentityLinkedData is published the first time a user signs in through the OpenID provider.
+type AuthUserIdentityLinkedData struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Issuer  string    `json:"issuer"`
+	Subject string    `json:"subject"`
+	Email   string    `json:"email"`
+}
+
+type AuthInvitationCreatedData struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	Email        string    `json:"email"`
+	RoleIDs      []int     `json:"role_ids"`
+	InvitedBy    uuid.UUID `json:"invited_by"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type AuthInvitationRevokedData struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	Email        string    `json:"email"`
+}
+
+type AuthInvitationAcceptedData struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Email        string    `json:"email"`
+	RoleIDs      []int     `json:"role_ids"`
+}
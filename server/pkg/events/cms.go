@@ -3,16 +3,19 @@ package events
 import "github.com/google/uuid"
 
 const (
-	CmsPagePublished     = "cms.page.published"
-	CmsPageDrafted       = "cms.page.drafted"
-	CmsPageArchived      = "cms.page.archived"
-	CmsPageLayoutUpdated = "cms.page.layout.updated"
+	CmsPagePublished       = "cms.page.published"
+	CmsPageDrafted         = "cms.page.drafted"
+	CmsPageArchived        = "cms.page.archived"
+	CmsPageLayoutUpdated   = "cms.page.layout.updated"
+	CmsPageMetadataUpdated = "cms.page.metadata.updated"
+	CmsPageAccessChanged   = "cms.page.access.changed"
 )
 
 type CmsPagePublishedData struct {
-	PageID uuid.UUID `json:"page_id"`
-	Title  string    `json:"title"`
-	Slug   string    `json:"slug"`
+	PageID         uuid.UUID `json:"page_id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	PreviousStatus string    `json:"previous_status"`
 }
 type CmsPageDraftedData struct {
 	PageID uuid.UUID `json:"page_id"`
@@ -21,9 +24,10 @@ type CmsPageDraftedData struct {
 }
 
 type CmsPageArchivedData struct {
-	PageID uuid.UUID `json:"page_id"`
-	Title  string    `json:"title"`
-	Slug   string    `json:"slug"`
+	PageID         uuid.UUID `json:"page_id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	PreviousStatus string    `json:"previous_status"`
 }
 
 type CmsPageLayoutUpdatedData struct {
@@ -31,3 +35,37 @@ type CmsPageLayoutUpdatedData struct {
 	Title  string    `json:"title"`
 	Slug   string    `json:"slug"`
 }
+
+// CmsPageMetadata is the editable metadata of a page as recorded before and after an update.
+type CmsPageMetadata struct {
+	Title          string   `json:"title"`
+	Slug           string   `json:"slug"`
+	SEODescription string   `json:"seo_description"`
+	Keywords       []string `json:"keywords"`
+}
+
+type CmsPageMetadataUpdatedData struct {
+	PageID uuid.UUID       `json:"page_id"`
+	Before CmsPageMetadata `json:"before"`
+	After  CmsPageMetadata `json:"after"`
+}
+
+// Kinds of change reported by CmsPageAccessChangedData.
+const (
+	CmsPageAccessOwner        = "owner"
+	CmsPageAccessGrantAdded   = "grant.added"
+	CmsPageAccessGrantRemoved = "grant.removed"
+)
+
+// CmsPageAccessChangedData is published when the owner or the grants of a page change.
+// Only the fields relevant to Change are set; a nil OwnerID means the page no longer has an owner.
+type CmsPageAccessChangedData struct {
+	PageID          uuid.UUID  `json:"page_id"`
+	Change          string     `json:"change"`
+	OwnerID         *uuid.UUID `json:"owner_id,omitempty"`
+	PreviousOwnerID *uuid.UUID `json:"previous_owner_id,omitempty"`
+	GrantID         *uuid.UUID `json:"grant_id,omitempty"`
+	UserID          *uuid.UUID `json:"user_id,omitempty"`
+	RoleID          *int       `json:"role_id,omitempty"`
+	Permission      string     `json:"permission,omitempty"`
+}
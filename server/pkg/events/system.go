@@ -1,6 +1,7 @@
 package events
 
 import (
+	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/authz"
 	"github.com/rubenalves-dev/template-fullstack/server/internal/platform/menu"
 )
 
@@ -10,8 +11,8 @@ const (
 )
 
 type SystemPermissionsRegisteredData struct {
-	Module      string   `json:"module"`
-	Permissions []string `json:"permissions"`
+	Module      string                       `json:"module"`
+	Permissions []authz.PermissionDefinition `json:"permissions"`
 }
 
 type SystemMenusRegisteredData struct {
@@ -6,14 +6,68 @@ import (
 )
 
 var (
-	ErrNotFound     = errors.New("resource not found")
-	ErrUnauthorized = errors.New("unauthorized")
-	ErrForbidden    = errors.New("forbidden")
-	ErrBadRequest   = errors.New("bad request")
-	ErrConflict     = errors.New("conflict")
+	ErrNotFound        = errors.New("resource not found")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrBadRequest      = errors.New("bad request")
+	ErrConflict        = errors.New("conflict")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrValidation      = errors.New("validation failed")
 )
 
+// CodedError refines one of the errors above with a more specific error code,
+// e.g. a 403 FORBIDDEN that the client should render as ACCOUNT_NOT_ACTIVATED.
+type CodedError struct {
+	Err  error
+	Code string
+	Msg  string
+}
+
+func NewCodedError(err error, code, msg string) *CodedError {
+	return &CodedError{Err: err, Code: code, Msg: msg}
+}
+
+func (e *CodedError) Error() string { return e.Msg }
+
+func (e *CodedError) Unwrap() error { return e.Err }
+
+// ValidationError lists what is wrong with the input, keyed by request field.
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+// NewValidationError reports problems with a single field.
+func NewValidationError(field string, problems ...string) *ValidationError {
+	return &ValidationError{Fields: map[string][]string{field: problems}}
+}
+
+// Add records problems for field.
+func (e *ValidationError) Add(field string, problems ...string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string][]string)
+	}
+	e.Fields[field] = append(e.Fields[field], problems...)
+}
+
+// OrNil returns nil when no problems were recorded, so callers can return it directly.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string { return ErrValidation.Error() }
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
 func MapError(err error) (int, string) {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		status, _ := MapError(coded.Err)
+		return status, coded.Code
+	}
+
 	switch {
 	case errors.Is(err, ErrNotFound):
 		return http.StatusNotFound, "RESOURCE_NOT_FOUND"
@@ -25,6 +79,10 @@ func MapError(err error) (int, string) {
 		return http.StatusBadRequest, "BAD_REQUEST"
 	case errors.Is(err, ErrConflict):
 		return http.StatusConflict, "CONFLICT"
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity, "VALIDATION_FAILED"
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests, "TOO_MANY_REQUESTS"
 	default:
 		return http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"
 	}
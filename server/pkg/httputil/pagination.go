@@ -0,0 +1,35 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	// MaxPage keeps Offset far from overflowing; no listing is expected to come near it.
+	MaxPage = 1_000_000
+)
+
+// Pagination is the page requested through the `page` and `page_size` query parameters.
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+// ParsePagination reads the paging query parameters, falling back to sane defaults for missing or invalid values.
+func ParsePagination(r *http.Request) Pagination {
+	p := Pagination{Page: 1, PageSize: DefaultPageSize}
+	if v, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && v > 0 {
+		p.Page = min(v, MaxPage)
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && v > 0 {
+		p.PageSize = min(v, MaxPageSize)
+	}
+	return p
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
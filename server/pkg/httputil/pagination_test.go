@@ -0,0 +1,28 @@
+package httputil
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Pagination
+	}{
+		{"", Pagination{Page: 1, PageSize: DefaultPageSize}},
+		{"?page=3&page_size=50", Pagination{Page: 3, PageSize: 50}},
+		{"?page=0&page_size=-1", Pagination{Page: 1, PageSize: DefaultPageSize}},
+		{"?page_size=1000", Pagination{Page: 1, PageSize: MaxPageSize}},
+		{"?page=9223372036854775807&page_size=100", Pagination{Page: MaxPage, PageSize: MaxPageSize}},
+	}
+	for _, tt := range tests {
+		got := ParsePagination(httptest.NewRequest("GET", "/users"+tt.query, nil))
+		if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.want, got)
+		}
+		if got.Offset() < 0 {
+			t.Errorf("%q: negative offset %d", tt.query, got.Offset())
+		}
+	}
+}
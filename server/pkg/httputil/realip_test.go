@@ -0,0 +1,49 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("parse trusted proxies: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:4000", nil, "203.0.113.7:4000"},
+		{"spoofed by a direct client", "203.0.113.7:4000", map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "1.2.3.4"}, "203.0.113.7:4000"},
+		{"through a trusted proxy", "10.1.2.3:4000", map[string]string{"X-Forwarded-For": "203.0.113.7"}, "203.0.113.7"},
+		{"spoofed through a trusted proxy", "10.1.2.3:4000", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 192.168.1.5"}, "203.0.113.7"},
+		{"real ip from a trusted proxy", "192.168.1.5:4000", map[string]string{"X-Real-IP": "203.0.113.7"}, "203.0.113.7"},
+		{"garbage from a trusted proxy", "10.1.2.3:4000", map[string]string{"X-Forwarded-For": "unknown"}, "10.1.2.3:4000"},
+		{"only proxies forwarded", "10.1.2.3:4000", map[string]string{"X-Forwarded-For": "10.9.9.9"}, "10.1.2.3:4000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			var got string
+			RealIP(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			})).ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"not-a-proxy"}); err == nil {
+		t.Fatal("expected an invalid proxy to be rejected")
+	}
+}
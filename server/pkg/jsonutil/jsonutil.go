@@ -10,9 +10,18 @@ type ResponseEnvelope struct {
 	Error *ErrorDetail `json:"error,omitempty"`
 }
 
+// PageResponse is the data of a paginated list endpoint.
+type PageResponse struct {
+	Items    interface{} `json:"items"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
 type ErrorDetail struct {
-	Code    string `json:"code"`
-	Message string `json:"msg"`
+	Code    string              `json:"code"`
+	Message string              `json:"msg"`
+	Fields  map[string][]string `json:"fields,omitempty"`
 }
 
 func RenderJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -33,3 +42,16 @@ func RenderError(w http.ResponseWriter, status int, code, msg string) {
 		},
 	})
 }
+
+// RenderFieldErrors renders an error that points at specific request fields.
+func RenderFieldErrors(w http.ResponseWriter, status int, code, msg string, fields map[string][]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ResponseEnvelope{
+		Error: &ErrorDetail{
+			Code:    code,
+			Message: msg,
+			Fields:  fields,
+		},
+	})
+}
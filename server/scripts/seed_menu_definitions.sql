@@ -2,8 +2,9 @@
 INSERT INTO menu_definitions (id, domain, label, path, icon, order_index, parent_id, permissions, visible)
 VALUES
     ('core:dashboard', 'auth', 'Dashboard', '/dashboard', 'dashboard', 0, NULL, ARRAY[]::text[], true),
-    ('auth:system', 'auth', 'System', NULL, 'settings', 90, NULL, ARRAY['auth.role.read'], true),
+    ('auth:system', 'auth', 'System', NULL, 'settings', 90, NULL, ARRAY['auth.role.read', 'auth.user.read'], true),
     ('auth:roles', 'auth', 'Roles', '/system/roles', NULL, 10, 'auth:system', ARRAY['auth.role.read'], true),
+    ('auth:users', 'auth', 'Users', '/system/users', NULL, 20, 'auth:system', ARRAY['auth.user.read'], true),
     ('cms:root', 'cms', 'CMS', NULL, 'article', 20, NULL, ARRAY['cms.page.read'], true),
     ('cms:pages', 'cms', 'Pages', '/cms/pages', NULL, 10, 'cms:root', ARRAY['cms.page.read'], true),
     ('cms:media', 'cms', 'Media', '/cms/media', NULL, 20, 'cms:root', ARRAY['cms.page.read'], true)